@@ -0,0 +1,99 @@
+// Command conformance checks that the CSI driver listening on a socket
+// implements the Modify service of modify.proto the way the sidecar
+// expects. It modifies the given volume.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/conformance"
+	"github.com/kubernetes-csi/csi-lib-utils/connection"
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"k8s.io/klog/v2"
+)
+
+var (
+	csiAddress       = flag.String("csi-address", "/run/csi/socket", "Address of the CSI driver socket.")
+	volumeID         = flag.String("volume-id", "", "ID of an existing volume the suite modifies. Required.")
+	parameters       = flag.String("parameters", "", "Comma-separated key=value parameters of a modification the driver accepts for the volume, e.g. `iops=4000,throughput=250`. Required.")
+	unknownParameter = flag.String("unknown-parameter", conformance.DefaultUnknownParameter, "Parameter the driver does not support.")
+	missingVolumeID  = flag.String("missing-volume-id", conformance.DefaultMissingVolumeID, "ID of a volume that does not exist.")
+	timeout          = flag.Duration("timeout", conformance.DefaultTimeout, "Timeout of every call to the driver.")
+	operationTimeout = flag.Duration("operation-timeout", conformance.DefaultOperationTimeout, "Maximum time to wait for an asynchronous modification to complete.")
+	pollInterval     = flag.Duration("poll-interval", conformance.DefaultPollInterval, "Interval at which the status of asynchronous modifications is polled.")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	params, err := parseParameters(*parameters)
+	if err != nil {
+		klog.Fatalf("Invalid --parameters: %v", err)
+	}
+
+	ctx := context.Background()
+	connectCtx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	conn, err := connection.Connect(connectCtx, *csiAddress, metrics.NewCSIMetricsManager(""))
+	if err != nil {
+		klog.Fatalf("Cannot connect to CSI driver at %q: %v", *csiAddress, err)
+	}
+	defer conn.Close()
+
+	results, err := conformance.Run(ctx, conn, conformance.Config{
+		VolumeID:         *volumeID,
+		Parameters:       params,
+		UnknownParameter: *unknownParameter,
+		MissingVolumeID:  *missingVolumeID,
+		Timeout:          *timeout,
+		OperationTimeout: *operationTimeout,
+		PollInterval:     *pollInterval,
+	})
+	if err != nil {
+		klog.Fatal(err.Error())
+	}
+	printResults(os.Stdout, results)
+	if !conformance.Succeeded(results) {
+		os.Exit(1)
+	}
+}
+
+// parseParameters parses comma-separated key=value pairs.
+func parseParameters(value string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", item)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+// printResults prints a line per check, followed by a summary.
+func printResults(out io.Writer, results []conformance.Result) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	counts := make(map[conformance.Outcome]int)
+	for _, result := range results {
+		counts[result.Outcome]++
+		line := result.Description
+		if result.Message != "" {
+			line += ": " + result.Message
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Outcome, result.Name, line)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "%d passed, %d failed, %d skipped\n", counts[conformance.Passed], counts[conformance.Failed], counts[conformance.Skipped])
+}
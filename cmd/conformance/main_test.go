@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/conformance"
+)
+
+func TestParseParameters(t *testing.T) {
+	params, err := parseParameters("iops=4000, throughput=250,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(params) != 2 || params["iops"] != "4000" || params["throughput"] != "250" {
+		t.Fatalf("unexpected parameters %v", params)
+	}
+
+	for _, value := range []string{"iops", "=4000"} {
+		if _, err := parseParameters(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	var out bytes.Buffer
+	printResults(&out, []conformance.Result{
+		{Name: "modify", Description: "ModifyVolumeProperties applies the modification", Outcome: conformance.Passed},
+		{Name: "unknown-parameter", Description: "Unknown parameters are rejected", Outcome: conformance.Failed, Message: "expected InvalidArgument, the request succeeded"},
+		{Name: "validate", Description: "Validation", Outcome: conformance.Skipped, Message: "ValidateVolumeModification is not implemented"},
+	})
+	for _, expected := range []string{
+		"PASS  modify",
+		"FAIL  unknown-parameter  Unknown parameters are rejected: expected InvalidArgument, the request succeeded",
+		"1 passed, 1 failed, 1 skipped",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// csiDriver is a CSI driver served by this process. Each driver has its own
+// connection, controllers, workqueues and leader election.
+type csiDriver struct {
+	address  string
+	name     string
+	client   *csi.ResilientClient
+	modifier modifier.Modifier
+	health   *healthChecker
+}
+
+// connectDriver connects to the CSI driver listening on address and
+// discovers its name.
+func connectDriver(address string, metricsManager metrics.CSIMetricsManager, kubeClient kubernetes.Interface) (*csiDriver, error) {
+	csiClient, err := csi.NewResilient(address, *timeout, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to CSI driver at %q: %w", address, err)
+	}
+	if err := csiClient.SupportsVolumeModification(context.TODO()); err != nil {
+		return nil, fmt.Errorf("CSI driver at %q does not support volume modification: %w", address, err)
+	}
+
+	driverName, err := getDriverName(csiClient, *timeout)
+	if err != nil {
+		return nil, fmt.Errorf("get driver name of CSI driver at %q failed: %w", address, err)
+	}
+	klog.V(2).InfoS("Connected to CSI driver", "name", driverName, "address", address)
+
+	csiModifier, err := modifier.NewFromClient(
+		driverName,
+		csiClient,
+		kubeClient,
+		*timeout,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csiDriver{
+		address:  address,
+		name:     driverName,
+		client:   csiClient,
+		modifier: csiModifier,
+		health:   newHealthChecker(csiClient, *timeout),
+	}, nil
+}
+
+func getDriverName(client csi.Client, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.GetDriverName(ctx)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/controller"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/fakedriver"
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const e2eDriverName = "fake.csi.k8s.io"
+
+// e2eMetrics holds the controller and workqueue metrics of the sidecars
+// started by the tests, as served on --http-endpoint.
+var e2eMetrics = k8smetrics.NewKubeRegistry()
+
+func newE2EVolume(name, volumeID string, annotations map[string]string) (*v1.PersistentVolumeClaim, *v1.PersistentVolume) {
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name), Annotations: annotations},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pv-" + name},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+	}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-" + name},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{Driver: e2eDriverName, VolumeHandle: volumeID},
+			},
+			ClaimRef: &v1.ObjectReference{Name: name, Namespace: "default", UID: pvc.UID},
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumeBound},
+	}
+	return pvc, pv
+}
+
+// startE2E serves d on a socket and runs the sidecar against it, wired as
+// main does, until the test ends.
+func startE2E(t *testing.T, d *fakedriver.Driver, kubeClient kubernetes.Interface) *csiDriver {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "csi.sock")
+	if err := d.Start(socket); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.Stop)
+
+	pollInterval, retryInterval := *modificationPollInterval, *retryIntervalStart
+	*modificationPollInterval, *retryIntervalStart = 50*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { *modificationPollInterval, *retryIntervalStart = pollInterval, retryInterval })
+
+	driver, err := connectDriver(socket, metrics.NewCSIMetricsManager(""), kubeClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(driver.client.CloseConnection)
+	if driver.name != e2eDriverName {
+		t.Fatalf("expected driver name %q, got %q", e2eDriverName, driver.name)
+	}
+
+	controller.RegisterMetrics(e2eMetrics)
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, *resyncPeriod)
+	mc := driver.health.track(func() controller.ModifyController {
+		return newControllers(driver, kubeClient, nil, informerFactory, nil)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runDriver(ctx, driver, kubeClient, "default", "test-pod", mc)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return driver
+}
+
+func waitForPVAnnotation(t *testing.T, kubeClient kubernetes.Interface, name, key, value string) {
+	t.Helper()
+	err := wait.PollUntilContextTimeout(context.TODO(), 20*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		pv, err := kubeClient.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return pv.Annotations[key] == value, nil
+	})
+	if err != nil {
+		t.Fatalf("PV %s was not annotated with %s=%s: %v", name, key, value, err)
+	}
+}
+
+// workqueueMetric returns the value of the workqueue metric of the claim
+// queue, or the number of observations of a histogram.
+func workqueueMetric(t *testing.T, name string) float64 {
+	t.Helper()
+	families, err := e2eMetrics.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "name" && label.GetValue() == e2eDriverName+"-modify-pvc" {
+					return m.GetGauge().GetValue() + m.GetCounter().GetValue() + float64(m.GetHistogram().GetSampleCount())
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// waitForQueueIdle waits until every PVC added to the claim queue has been
+// processed. Items waiting for a retry are not counted.
+func waitForQueueIdle(t *testing.T) {
+	t.Helper()
+	err := wait.PollUntilContextTimeout(context.TODO(), 20*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		return workqueueMetric(t, "workqueue_depth") == 0 &&
+			workqueueMetric(t, "workqueue_adds_total") == workqueueMetric(t, "workqueue_work_duration_seconds"), nil
+	})
+	if err != nil {
+		t.Fatalf("claim queue did not become idle: %v", err)
+	}
+}
+
+func waitForPVCStatus(t *testing.T, kubeClient kubernetes.Interface, name, param, state, failure string) {
+	t.Helper()
+	key := fmt.Sprintf(controller.AnnotationStatusPrefixPattern, e2eDriverName, param)
+	err := wait.PollUntilContextTimeout(context.TODO(), 20*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		pvc, err := kubeClient.CoreV1().PersistentVolumeClaims("default").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		var status struct {
+			State   string `json:"state"`
+			Failure string `json:"failure"`
+		}
+		if json.Unmarshal([]byte(pvc.Annotations[key]), &status) != nil {
+			return false, nil
+		}
+		return status.State == state && status.Failure == failure, nil
+	})
+	if err != nil {
+		t.Fatalf("PVC %s did not report %s %s for %s: %v", name, failure, state, param, err)
+	}
+}
+
+func TestE2E_ModifyVolume(t *testing.T) {
+	minIOPS := int64(3000)
+	d := fakedriver.New(e2eDriverName,
+		fakedriver.WithParameters(&modifyrpc.ParameterSchema{Name: "iops", Type: modifyrpc.ParameterSchema_INTEGER, MinValue: &minIOPS}),
+		fakedriver.WithAsyncModification(100*time.Millisecond),
+	)
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+	pvc, pv := newE2EVolume("data", "vol-1", map[string]string{e2eDriverName + "/iops": "5000"})
+	kubeClient := fake.NewClientset(pvc, pv)
+
+	driver := startE2E(t, d, kubeClient)
+
+	waitForPVAnnotation(t, kubeClient, "pv-data", e2eDriverName+"/iops", "5000")
+	if params, _ := d.Volume("vol-1"); params["iops"] != "5000" {
+		t.Fatalf("expected the driver to have modified the volume, got %v", params)
+	}
+	requests := d.Requests()
+	if len(requests) == 0 || requests[0].GetContext()["csi.storage.k8s.io/pvc/name"] != "data" {
+		t.Fatalf("expected the request context to be sent to the driver, got %v", requests)
+	}
+
+	checkers := &healthCheckers{}
+	checkers.add(driver.name, driver.health)
+	rec := serve(checkers.readyz)
+	if !strings.Contains(rec.Body.String(), "[+]csi-driver ok: connected") {
+		t.Fatalf("expected the driver to be reported connected, got %s", rec.Body.String())
+	}
+}
+
+func TestE2E_TerminalError(t *testing.T) {
+	d := fakedriver.New(e2eDriverName)
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+	d.SetFailure("vol-1", status.Error(codes.InvalidArgument, "iops cannot be changed on this volume type"))
+	pvc, pv := newE2EVolume("data", "vol-1", map[string]string{e2eDriverName + "/iops": "5000"})
+	kubeClient := fake.NewClientset(pvc, pv)
+
+	retries := workqueueMetric(t, "workqueue_retries_total")
+	startE2E(t, d, kubeClient)
+
+	var message string
+	err := wait.PollUntilContextTimeout(context.TODO(), 20*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		events, err := kubeClient.CoreV1().Events("default").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, event := range events.Items {
+			if event.Reason == controller.VolumeModificationFailed {
+				message = event.Message
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("no %s event: %v", controller.VolumeModificationFailed, err)
+	}
+	if !strings.Contains(message, "terminal error InvalidArgument") || !strings.Contains(message, "iops cannot be changed on this volume type") {
+		t.Fatalf("expected event to carry the driver's error, got %q", message)
+	}
+	waitForPVCStatus(t, kubeClient, "data", "iops", controller.ModificationStateFailed, controller.FailureTerminal)
+	waitForQueueIdle(t)
+	if n := workqueueMetric(t, "workqueue_retries_total") - retries; n != 0 {
+		t.Fatalf("expected terminal error not to be retried, got %v retries", n)
+	}
+	if requests := d.Requests(); len(requests) != 1 {
+		t.Fatalf("expected terminal error not to be retried, got %d requests", len(requests))
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/controller"
+	"k8s.io/klog/v2"
+)
+
+// healthChecker tracks the health of a CSI driver and of the controllers
+// modifying its volumes.
+type healthChecker struct {
+	csiClient csi.Client
+	timeout   time.Duration
+
+	mu sync.Mutex
+	// running is the controller started by this pod, nil while it is not
+	// the leader.
+	running controller.ModifyController
+	// leaseCheck reports whether the Lease used to elect the leader is
+	// fresh. It is nil when leader election is disabled.
+	leaseCheck func() error
+}
+
+func newHealthChecker(csiClient csi.Client, timeout time.Duration) *healthChecker {
+	return &healthChecker{
+		csiClient: csiClient,
+		timeout:   timeout,
+	}
+}
+
+// track wraps mc so that the controllers it creates are reported by /readyz
+// while they run.
+func (h *healthChecker) track(mc func() controller.ModifyController) func() controller.ModifyController {
+	return func() controller.ModifyController {
+		return &trackedController{ModifyController: mc(), health: h}
+	}
+}
+
+func (h *healthChecker) setLeaseCheck(check func() error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leaseCheck = check
+}
+
+type trackedController struct {
+	controller.ModifyController
+	health *healthChecker
+}
+
+func (t *trackedController) Run(workers int, ctx context.Context) {
+	t.health.mu.Lock()
+	t.health.running = t
+	t.health.mu.Unlock()
+
+	defer func() {
+		t.health.mu.Lock()
+		defer t.health.mu.Unlock()
+		// A newer controller may already run while this one drained.
+		if t.health.running == t {
+			t.health.running = nil
+		}
+	}()
+
+	t.ModifyController.Run(workers, ctx)
+}
+
+// probe reports whether the CSI driver answers probes on its socket.
+func (h *healthChecker) probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	return h.csiClient.Probe(ctx)
+}
+
+type readinessCheck struct {
+	name   string
+	status string
+	err    error
+}
+
+func (h *healthChecker) readinessChecks() []readinessCheck {
+	h.mu.Lock()
+	running, leaseCheck := h.running, h.leaseCheck
+	h.mu.Unlock()
+
+	leader := readinessCheck{name: "leader", status: "standby"}
+	informers := readinessCheck{name: "informer-sync", status: "not leading"}
+	if running != nil {
+		leader.status = "leading"
+		informers.status = "synced"
+		if !running.HasSynced() {
+			informers.err = fmt.Errorf("PV and PVC informers have not synced")
+		}
+	}
+
+	lease := readinessCheck{name: "lease", status: "leader election disabled"}
+	if leaseCheck != nil {
+		lease.status = "fresh"
+		lease.err = leaseCheck()
+	}
+
+	driver := readinessCheck{name: "csi-driver", status: "connected"}
+	if resilient, ok := h.csiClient.(interface{ Available() bool }); ok && !resilient.Available() {
+		driver.err = fmt.Errorf("connection to the CSI driver was lost, reconnecting")
+	}
+	return []readinessCheck{leader, informers, lease, driver}
+}
+
+// healthCheckers serves the /healthz and /readyz endpoints for every CSI
+// driver served by this process.
+type healthCheckers struct {
+	names    []string
+	checkers []*healthChecker
+}
+
+func (h *healthCheckers) add(driverName string, checker *healthChecker) {
+	h.names = append(h.names, driverName)
+	h.checkers = append(h.checkers, checker)
+}
+
+// healthz reports whether every CSI driver answers probes on its socket.
+func (h *healthCheckers) healthz(w http.ResponseWriter, r *http.Request) {
+	for i, checker := range h.checkers {
+		if err := checker.probe(r.Context()); err != nil {
+			klog.V(2).InfoS("Health check failed", "driver", h.names[i], "err", err)
+			http.Error(w, fmt.Sprintf("CSI driver %s probe failed: %v", h.names[i], err), http.StatusInternalServerError)
+			return
+		}
+	}
+	fmt.Fprint(w, "ok")
+}
+
+// readyz reports whether the informers of the running controllers synced,
+// whether the Leases followed for leader election are fresh and whether the
+// CSI drivers are connected. A replica that is not the leader is ready, as
+// it can take over at any time. When several drivers are served, the checks
+// are prefixed with the driver name.
+func (h *healthCheckers) readyz(w http.ResponseWriter, r *http.Request) {
+	var body strings.Builder
+	failed := false
+	for i, checker := range h.checkers {
+		for _, check := range checker.readinessChecks() {
+			name := check.name
+			if len(h.checkers) > 1 {
+				name = h.names[i] + "/" + name
+			}
+			if check.err != nil {
+				failed = true
+				fmt.Fprintf(&body, "[-]%s failed: %v\n", name, check.err)
+			} else {
+				fmt.Fprintf(&body, "[+]%s ok: %s\n", name, check.status)
+			}
+		}
+	}
+
+	if failed {
+		klog.V(2).InfoS("Readiness check failed", "checks", body.String())
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, body.String())
+		fmt.Fprint(w, "readyz check failed\n")
+		return
+	}
+	fmt.Fprint(w, body.String())
+	fmt.Fprint(w, "readyz check passed\n")
+}
+
+// leaseFreshness tracks the updates of the external-resizer Lease, which
+// the resizer renews every few seconds while it has a leader.
+type leaseFreshness struct {
+	mu         sync.Mutex
+	lastUpdate time.Time
+	timeout    time.Duration
+}
+
+func newLeaseFreshness(timeout time.Duration) *leaseFreshness {
+	return &leaseFreshness{
+		lastUpdate: time.Now(),
+		timeout:    timeout,
+	}
+}
+
+func (l *leaseFreshness) observe() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastUpdate = time.Now()
+}
+
+func (l *leaseFreshness) check() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if since := time.Since(l.lastUpdate); since > l.timeout {
+		return fmt.Errorf("no lease update received for %v", since.Round(time.Second))
+	}
+	return nil
+}
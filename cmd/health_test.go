@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/controller"
+
+	"github.com/golang/mock/gomock"
+)
+
+func serve(handler http.HandlerFunc) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder
+}
+
+func TestHealthz(t *testing.T) {
+	client := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+	health := newHealthChecker(client, time.Second)
+	checkers := &healthCheckers{}
+	checkers.add("ebs.csi.aws.com", health)
+
+	if rec := serve(checkers.healthz); rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	client.SetProbeError(errors.New("connection refused"))
+	rec := serve(checkers.healthz)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "connection refused") {
+		t.Fatalf("expected probe error in body, got %q", rec.Body.String())
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModifyController := controller.NewMockModifyController(ctrl)
+	synced := false
+	mockModifyController.EXPECT().HasSynced().DoAndReturn(func() bool { return synced }).AnyTimes()
+
+	running := make(chan struct{})
+	stop := make(chan struct{})
+	mockModifyController.EXPECT().Run(gomock.Eq(workerCount), gomock.Not(gomock.Nil())).Do(
+		func(_ int, _ context.Context) {
+			close(running)
+			<-stop
+		},
+	).Times(1)
+
+	health := newHealthChecker(csi.NewFakeClient("ebs.csi.aws.com", true, false), time.Second)
+	checkers := &healthCheckers{}
+	checkers.add("ebs.csi.aws.com", health)
+	leaseErr := error(nil)
+	health.setLeaseCheck(func() error { return leaseErr })
+
+	rec := serve(checkers.readyz)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "[+]leader ok: standby") {
+		t.Fatalf("expected standby replica to be ready, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	mc := health.track(func() controller.ModifyController { return mockModifyController })
+	done := make(chan struct{})
+	go func() {
+		mc().Run(workerCount, context.Background())
+		close(done)
+	}()
+	<-running
+
+	rec = serve(checkers.readyz)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "[-]informer-sync failed") {
+		t.Fatalf("expected leader with unsynced informers not to be ready, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	synced = true
+	rec = serve(checkers.readyz)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "[+]leader ok: leading") {
+		t.Fatalf("expected synced leader to be ready, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	leaseErr = errors.New("no lease update received for 10m0s")
+	rec = serve(checkers.readyz)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "[-]lease failed") {
+		t.Fatalf("expected stale lease to fail readiness, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	close(stop)
+	<-done
+	leaseErr = nil
+	rec = serve(checkers.readyz)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "[+]leader ok: standby") {
+		t.Fatalf("expected replica to be standby after the controller stopped, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// reconnectingClient is a csi.Client reporting whether it is connected, as
+// csi.ResilientClient does.
+type reconnectingClient struct {
+	*csi.FakeClient
+	available bool
+}
+
+func (c *reconnectingClient) Available() bool {
+	return c.available
+}
+
+func TestReadyz_DriverConnection(t *testing.T) {
+	client := &reconnectingClient{FakeClient: csi.NewFakeClient("ebs.csi.aws.com", true, false), available: true}
+	health := newHealthChecker(client, time.Second)
+	checkers := &healthCheckers{}
+	checkers.add("ebs.csi.aws.com", health)
+
+	rec := serve(checkers.readyz)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "[+]csi-driver ok: connected") {
+		t.Fatalf("expected connected replica to be ready, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	client.available = false
+	rec = serve(checkers.readyz)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "[-]csi-driver failed") {
+		t.Fatalf("expected replica reconnecting to the driver not to be ready, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHealthCheckers_MultipleDrivers(t *testing.T) {
+	ebsClient := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+	efsClient := csi.NewFakeClient("efs.csi.aws.com", true, false)
+	checkers := &healthCheckers{}
+	checkers.add("ebs.csi.aws.com", newHealthChecker(ebsClient, time.Second))
+	checkers.add("efs.csi.aws.com", newHealthChecker(efsClient, time.Second))
+
+	rec := serve(checkers.readyz)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	for _, check := range []string{"[+]ebs.csi.aws.com/leader ok", "[+]efs.csi.aws.com/leader ok"} {
+		if !strings.Contains(rec.Body.String(), check) {
+			t.Fatalf("expected %q in body, got %q", check, rec.Body.String())
+		}
+	}
+
+	if rec := serve(checkers.healthz); rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	efsClient.SetProbeError(errors.New("connection refused"))
+	rec = serve(checkers.healthz)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "CSI driver efs.csi.aws.com probe failed") {
+		t.Fatalf("expected failing driver to be named, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLeaseFreshness(t *testing.T) {
+	freshness := newLeaseFreshness(50 * time.Millisecond)
+	if err := freshness.check(); err != nil {
+		t.Fatalf("expected fresh lease, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if err := freshness.check(); err == nil {
+		t.Fatal("expected stale lease error")
+	}
+
+	freshness.observe()
+	if err := freshness.check(); err != nil {
+		t.Fatalf("expected fresh lease after update, got %v", err)
+	}
+}
@@ -6,20 +6,28 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
-	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
 	"github.com/awslabs/volume-modifier-for-k8s/pkg/controller"
-	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/webhook"
 	"github.com/kubernetes-csi/csi-lib-utils/metrics"
 	"github.com/kubernetes-csi/external-resizer/pkg/util"
 	v1 "k8s.io/api/coordination/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 )
@@ -30,15 +38,27 @@ var (
 	resyncPeriod    = flag.Duration("resync-period", time.Minute*10, "Resync period for cache")
 	workers         = flag.Int("workers", 10, "Concurrency to process multiple modification requests")
 
-	csiAddress = flag.String("csi-address", "/run/csi/socket", "Address of the CSI driver socket.")
-	timeout    = flag.Duration("timeout", 10*time.Second, "Timeout for waiting for CSI driver socket.")
+	// csiAddresses holds the repeatable --csi-address flag.
+	csiAddresses stringList
+	timeout      = flag.Duration("timeout", 10*time.Second, "Timeout for waiting for CSI driver socket.")
 
 	showVersion = flag.Bool("version", false, "Show version")
 
 	retryIntervalStart = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of failed volume modification. It exponentially increases with each failure, up to retry-interval-max.")
 	retryIntervalMax   = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of failed volume modification.")
 
-	enableLeaderElection        = flag.Bool("leader-election", false, "Enable leader election.")
+	dryRun                   = flag.Bool("dry-run", false, "Only validate requested volume modifications with the CSI driver instead of applying them.")
+	driftCheckInterval       = flag.Duration("drift-check-interval", 0, "Interval at which volume properties reported by the CSI driver are compared with the last applied modification. Zero disables drift detection.")
+	driftReapply             = flag.Bool("drift-reapply", false, "Modify volumes whose properties drifted from the last applied modification back to the desired state.")
+	modificationCooldown     = flag.Duration("modification-cooldown", 0, "Minimum time between two modifications of the same volume, measured from the last successful modification. The CSI driver's advertised cooldown is used if it is longer.")
+	shutdownTimeout          = flag.Duration("shutdown-timeout", 30*time.Second, "Maximum time to wait for in-flight volume modifications to finish when the sidecar is terminated or loses leadership. Should be lower than the pod's terminationGracePeriodSeconds.")
+	modificationPollInterval = flag.Duration("modification-poll-interval", 15*time.Second, "Interval at which the CSI driver is polled for the status of volume modifications that complete asynchronously.")
+	reqContextLabels         = flag.String("reqcontext-pvc-labels", "", "Comma-separated list of PVC labels passed to the CSI driver in the context of every modification, under the `volumemodifier.k8s.aws/pvc/label/` prefix.")
+	reqContextAnnotations    = flag.String("reqcontext-pvc-annotations", "", "Comma-separated list of PVC annotations passed to the CSI driver in the context of every modification, under the `volumemodifier.k8s.aws/pvc/annotation/` prefix.")
+	enableVolumeModification = flag.Bool("enable-volume-modification-api", false, "Also apply VolumeModification and VolumeModificationPlan objects and enforce VolumeModificationQuota objects (volumemodifier.k8s.aws/v1alpha1). Requires their CRDs to be installed.")
+
+	enableLeaderElection        = flag.Bool("leader-election", false, "Enable leader election using a Lease owned by this sidecar. Mutually exclusive with --follow-resizer-lease.")
+	followResizerLease          = flag.Bool("follow-resizer-lease", false, "Only run the controller while this pod holds the external-resizer Lease of the same driver. Requires leader election to be enabled in the external-resizer. Mutually exclusive with --leader-election.")
 	leaderElectionNamespace     = flag.String("leader-election-namespace", "", "Namespace where the leader election resource lives. Defaults to the pod namespace if not set.")
 	leaderElectionLeaseDuration = flag.Duration("leader-election-lease-duration", 15*time.Second, "Duration, in seconds, that non-leader candidates will wait to force acquire leadership. Defaults to 15 seconds.")
 	leaderElectionRenewDeadline = flag.Duration("leader-election-renew-deadline", 10*time.Second, "Duration, in seconds, that the acting leader will retry refreshing leadership before giving up. Defaults to 10 seconds.")
@@ -47,6 +67,10 @@ var (
 	httpEndpoint = flag.String("http-endpoint", "", "The TCP network address where the HTTP server for diagnostics, including metrics and leader election health check, will listen (example: `:8080`). The default is empty string, which means the server is disabled. Only one of `--metrics-address` and `--http-endpoint` can be set.")
 	metricsPath  = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
 
+	webhookAddress     = flag.String("webhook-address", "", "The TCP network address where the validating admission webhook for PVC modification annotations will listen (example: `:9443`). The default is empty string, which means the webhook is disabled.")
+	webhookTLSCertFile = flag.String("webhook-tls-cert-file", "", "Path to the TLS certificate served by the validating admission webhook.")
+	webhookTLSKeyFile  = flag.String("webhook-tls-private-key-file", "", "Path to the private key of the TLS certificate served by the validating admission webhook.")
+
 	kubeAPIQPS   = flag.Float64("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver. Defaults to 5.0.")
 	kubeAPIBurst = flag.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver. Defaults to 10.")
 
@@ -54,7 +78,10 @@ var (
 	version = "<unknown>"
 )
 
+const defaultCSIAddress = "/run/csi/socket"
+
 func main() {
+	flag.Var(&csiAddresses, "csi-address", "Address of a CSI driver socket. Repeat the flag, or separate addresses with commas, to serve several CSI drivers from this process. Defaults to `"+defaultCSIAddress+"`.")
 	klog.InitFlags(nil)
 	flag.Set("logtostderr", "true")
 	flag.Parse()
@@ -64,7 +91,16 @@ func main() {
 		os.Exit(0)
 	}
 	klog.Infof("Version : %s", version)
-	klog.InfoS("Leader election must be enabled in the external-resizer CSI sidecar")
+	if *enableLeaderElection && *followResizerLease {
+		klog.Fatal("--leader-election and --follow-resizer-lease are mutually exclusive")
+	}
+	if *followResizerLease {
+		klog.InfoS("Leader election must be enabled in the external-resizer CSI sidecar")
+	}
+	if !*enableLeaderElection && !*followResizerLease {
+		// Earlier versions followed the external-resizer Lease unconditionally.
+		klog.Warning("Neither --leader-election nor --follow-resizer-lease is set, every replica modifies volumes. Set --follow-resizer-lease to keep following the external-resizer Lease as before, or --leader-election, when running more than one replica")
+	}
 
 	// https://github.com/kubernetes-csi/csi-lib-utils/blob/master/leaderelection/leader_election.go#L212-L214
 	leaseIdentity, err := os.Hostname()
@@ -72,7 +108,7 @@ func main() {
 		klog.Fatal("Failed to get hostname for lease identity", "err", err)
 	}
 	podNamespace := os.Getenv("POD_NAMESPACE")
-	if podNamespace == "" {
+	if podNamespace == "" && (*followResizerLease || (*enableLeaderElection && *leaderElectionNamespace == "")) {
 		klog.Fatal("POD_NAMESPACE environment variable is not set")
 	}
 
@@ -95,35 +131,48 @@ func main() {
 		klog.Fatal(err.Error())
 	}
 
-	mux := http.NewServeMux()
-	metricsManager := metrics.NewCSIMetricsManager("" /* driverName */)
-	csiClient, err := csi.New(*csiAddress, *timeout, metricsManager)
-	if err != nil {
-		klog.Fatal(err.Error())
-	}
-	if err := csiClient.SupportsVolumeModification(context.TODO()); err != nil {
-		klog.Fatalf("CSI driver does not support volume modification: %v", err)
+	var vmClient versioned.Interface
+	if *enableVolumeModification {
+		vmClient, err = versioned.NewForConfig(config)
+		if err != nil {
+			klog.Fatal(err.Error())
+		}
 	}
 
-	driverName, err := getDriverName(csiClient, *timeout)
-	if err != nil {
-		klog.Fatal(fmt.Errorf("get driver name failed: %v", err))
+	addresses := []string(csiAddresses)
+	if len(addresses) == 0 {
+		addresses = []string{defaultCSIAddress}
 	}
-	klog.V(2).Infof("CSI driver name: %q", driverName)
 
-	csiModifier, err := modifier.NewFromClient(
-		driverName,
-		csiClient,
-		kubeClient,
-		*timeout,
-	)
-	if err != nil {
-		klog.Fatal(err.Error())
+	mux := http.NewServeMux()
+	metricsManager := metrics.NewCSIMetricsManager("" /* driverName */)
+	var drivers []*csiDriver
+	addressOf := make(map[string]string)
+	for _, address := range addresses {
+		driver, err := connectDriver(address, metricsManager, kubeClient)
+		if err != nil {
+			klog.Fatal(err.Error())
+		}
+		if other, ok := addressOf[driver.name]; ok {
+			klog.Fatalf("CSI driver %q is served on both %q and %q", driver.name, other, address)
+		}
+		addressOf[driver.name] = address
+		drivers = append(drivers, driver)
 	}
 
+	health := &healthCheckers{}
+	for _, driver := range drivers {
+		health.add(driver.name, driver.health)
+	}
 	if addr != "" {
+		controller.RegisterMetrics(metricsManager.GetRegistry())
 		metricsManager.RegisterToServer(mux, *metricsPath)
-		metricsManager.SetDriverName(driverName)
+		mux.HandleFunc("/healthz", health.healthz)
+		mux.HandleFunc("/readyz", health.readyz)
+		// The gRPC metrics of several drivers share the connection metrics.
+		if len(drivers) == 1 {
+			metricsManager.SetDriverName(drivers[0].name)
+		}
 		go func() {
 			klog.Infof("ServeMux listening at %q", addr)
 			err := http.ListenAndServe(addr, mux)
@@ -133,20 +182,224 @@ func main() {
 		}()
 	}
 
-	modifierName := csiModifier.Name()
-	mc := func() controller.ModifyController {
-		return controller.NewModifyController(
-			modifierName,
-			csiModifier,
-			kubeClient,
-			*resyncPeriod,
-			informers.NewSharedInformerFactory(kubeClient, *resyncPeriod),
-			workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
-			true, /* retryFailure */
-		)
+	if *webhookAddress != "" {
+		if *webhookTLSCertFile == "" || *webhookTLSKeyFile == "" {
+			klog.Fatal("--webhook-tls-cert-file and --webhook-tls-private-key-file must be set with --webhook-address")
+		}
+		// Every replica serves the webhook, regardless of leadership.
+		webhookMux := http.NewServeMux()
+		for _, driver := range drivers {
+			validator := webhook.NewValidator(driver.name, driver.modifier, kubeClient)
+			webhookMux.Handle("/validate/"+driver.name, validator)
+			if len(drivers) == 1 {
+				webhookMux.Handle("/validate", validator)
+			}
+		}
+		go func() {
+			klog.Infof("Validating admission webhook listening at %q", *webhookAddress)
+			err := http.ListenAndServeTLS(*webhookAddress, *webhookTLSCertFile, *webhookTLSKeyFile, webhookMux)
+			if err != nil {
+				klog.Fatalf("Failed to start validating admission webhook at specified address (%q): %s", *webhookAddress, err)
+			}
+		}()
+	}
+
+	// The controllers of every driver share the informers, which outlive
+	// the controllers started each time leadership is acquired. Each
+	// controller removes its event handlers from them when it stops.
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, *resyncPeriod)
+	var vmInformerFactory externalversions.SharedInformerFactory
+	if vmClient != nil {
+		vmInformerFactory = externalversions.NewSharedInformerFactory(vmClient, *resyncPeriod)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, driver := range drivers {
+		mc := driver.health.track(func() controller.ModifyController {
+			return newControllers(driver, kubeClient, vmClient, informerFactory, vmInformerFactory)
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runDriver(ctx, driver, kubeClient, podNamespace, leaseIdentity, mc)
+		}()
+	}
+	wg.Wait()
+	klog.InfoS("Exiting")
+}
+
+// newControllers returns the controllers modifying the volumes of driver.
+func newControllers(driver *csiDriver, kubeClient kubernetes.Interface, vmClient versioned.Interface, informerFactory informers.SharedInformerFactory, vmInformerFactory externalversions.SharedInformerFactory) controller.ModifyController {
+	opts := []controller.Option{
+		controller.WithModificationPollInterval(*modificationPollInterval),
+		controller.WithDryRun(*dryRun),
+		controller.WithDriftCheck(*driftCheckInterval, *driftReapply),
+		controller.WithModificationCooldown(*modificationCooldown),
+		controller.WithShutdownTimeout(*shutdownTimeout),
+		controller.WithRequestContextPassThrough(splitList(*reqContextLabels), splitList(*reqContextAnnotations)),
+		controller.WithDriverAvailability(driver.client),
+	}
+	if vmClient != nil {
+		opts = append(opts, controller.WithModificationQuotas(vmInformerFactory))
 	}
+	annotationController := controller.NewModifyController(
+		driver.name,
+		driver.modifier,
+		kubeClient,
+		*resyncPeriod,
+		informerFactory,
+		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		true, /* retryFailure */
+		opts...,
+	)
+	if vmClient == nil {
+		return annotationController
+	}
+	vmc := controller.NewVolumeModificationController(
+		driver.name,
+		driver.modifier,
+		kubeClient,
+		vmClient,
+		*resyncPeriod,
+		informerFactory,
+		vmInformerFactory,
+		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+	)
+	pc := controller.NewVolumeModificationPlanController(
+		driver.name,
+		kubeClient,
+		vmClient,
+		*resyncPeriod,
+		informerFactory,
+		vmInformerFactory,
+		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+	)
+	qc := controller.NewVolumeModificationQuotaController(
+		driver.name,
+		vmClient,
+		*resyncPeriod,
+		informerFactory,
+		vmInformerFactory,
+		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+	)
+	return controller.NewGroup(annotationController, vmc, pc, qc)
+}
+
+// runDriver runs the controllers of driver, subject to the leader election
+// of the driver when enabled, until ctx is cancelled.
+func runDriver(ctx context.Context, driver *csiDriver, kubeClient kubernetes.Interface, podNamespace, leaseIdentity string, mc func() controller.ModifyController) {
+	switch {
+	case *enableLeaderElection:
+		namespace := *leaderElectionNamespace
+		if namespace == "" {
+			namespace = podNamespace
+		}
+		watchdog := leaderelection.NewLeaderHealthzAdaptor(*leaderElectionLeaseDuration)
+		driver.health.setLeaseCheck(func() error { return watchdog.Check(nil) })
+		runWithLeaderElection(ctx, kubeClient, leaderElectionLeaseName(driver.name), namespace, leaseIdentity, mc, watchdog)
+	case *followResizerLease:
+		freshness := newLeaseFreshness(*resyncPeriod)
+		driver.health.setLeaseCheck(freshness.check)
+		followResizerLeaseHolder(ctx, kubeClient, driver.name, podNamespace, leaseIdentity, mc, freshness)
+	default:
+		klog.InfoS("Leader election is disabled, starting ModifyController", "driver", driver.name)
+		mc().Run(*workers, ctx)
+	}
+}
+
+// leaderElectionLeaseName returns the name of the Lease used by this sidecar
+// when --leader-election is set. It is distinct from the external-resizer
+// Lease so both sidecars can elect their leaders independently.
+func leaderElectionLeaseName(driverName string) string {
+	return "volume-modifier-for-k8s-" + util.SanitizeName(driverName)
+}
+
+// runWithLeaderElection campaigns for the given Lease and runs the controller
+// for as long as this pod holds it. A new controller is started each time
+// leadership is (re)acquired. Once ctx is cancelled, the controller is stopped
+// and the Lease is only released after its in-flight modifications finished,
+// so that the next leader does not issue them again. watchdog, if not nil,
+// is used to report whether this pod keeps renewing the Lease while leading.
+func runWithLeaderElection(ctx context.Context, kubeClient kubernetes.Interface, leaseName, namespace, leaseIdentity string, mc func() controller.ModifyController, watchdog *leaderelection.HealthzAdaptor) {
+	// The election outlives ctx while this pod leads to keep the Lease
+	// renewed during the controller shutdown.
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	defer stopElection()
+	// running counts the controllers started by this pod, including ones
+	// still draining after leadership was lost.
+	var running atomic.Int32
+	stopAfterCancel := context.AfterFunc(ctx, func() {
+		if running.Load() == 0 {
+			stopElection()
+		}
+	})
+	defer stopAfterCancel()
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseName,
+			Namespace: namespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: leaseIdentity,
+		},
+	}
+	config := leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            leaseName,
+		LeaseDuration:   *leaderElectionLeaseDuration,
+		RenewDeadline:   *leaderElectionRenewDeadline,
+		RetryPeriod:     *leaderElectionRetryPeriod,
+		ReleaseOnCancel: true,
+		WatchDog:        watchdog,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				running.Add(1)
+				defer running.Add(-1)
+
+				runCtx, cancel := context.WithCancel(leaderCtx)
+				defer cancel()
+				stopRun := context.AfterFunc(ctx, cancel)
+				defer stopRun()
+
+				klog.InfoS("Became leader, starting ModifyController", "lease", klog.KRef(namespace, leaseName), "leaseIdentity", leaseIdentity)
+				mc().Run(*workers, runCtx)
+				if ctx.Err() != nil {
+					stopElection()
+				}
+			},
+			OnStoppedLeading: func() {
+				klog.InfoS("Stopped leading, ModifyController stopped", "lease", klog.KRef(namespace, leaseName), "leaseIdentity", leaseIdentity)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != leaseIdentity {
+					klog.InfoS("New leader elected", "lease", klog.KRef(namespace, leaseName), "currentLeader", identity)
+				}
+			},
+		},
+	}
+
+	klog.InfoS("Starting leader election", "lease", klog.KRef(namespace, leaseName), "leaseIdentity", leaseIdentity)
+	for electionCtx.Err() == nil {
+		leaderelection.RunOrDie(electionCtx, config)
+	}
+}
+
+// followResizerLeaseHolder runs the controller only while this pod holds the
+// external-resizer Lease of the same driver. It returns once ctx is cancelled
+// and the controller has stopped. Every update of the Lease is reported to
+// freshness.
+func followResizerLeaseHolder(ctx context.Context, kubeClient kubernetes.Interface, driverName, podNamespace, leaseIdentity string, mc func() controller.ModifyController, freshness *leaseFreshness) {
 	leaseChannel := make(chan *v1.Lease)
-	go leaseHandler(leaseIdentity, mc, leaseChannel)
+	handlerDone := make(chan struct{})
+	go func() {
+		leaseHandler(leaseIdentity, mc, leaseChannel)
+		close(handlerDone)
+	}()
 
 	informerFactoryLeases := informers.NewSharedInformerFactoryWithOptions(kubeClient, *resyncPeriod, informers.WithNamespace(podNamespace))
 	leaseInformer := informerFactoryLeases.Coordination().V1().Leases().Informer()
@@ -159,16 +412,24 @@ func main() {
 			}
 			resizerLeaseName := "external-resizer-" + util.SanitizeName(driverName)
 			if lease.Name == resizerLeaseName {
+				freshness.observe()
 				leaseChannel <- lease
 			}
 		},
 	})
-	informerFactoryLeases.Start(wait.NeverStop)
-	leaseInformer.Run(wait.NeverStop)
+	leaseInformer.Run(ctx.Done())
+
+	close(leaseChannel)
+	<-handlerDone
 }
 
+// leaseHandler starts and stops the controller as the external-resizer Lease
+// changes holders. When leaseChannel is closed, it stops the controller and
+// waits for every controller it started to finish draining.
 func leaseHandler(leaseIdentity string, mc func() controller.ModifyController, leaseChannel chan *v1.Lease) {
 	var cancel context.CancelFunc = nil
+	var running sync.WaitGroup
+	defer running.Wait()
 
 	klog.InfoS("leaseHandler: Looking for external-resizer lease holder")
 
@@ -191,7 +452,11 @@ func leaseHandler(leaseIdentity string, mc func() controller.ModifyController, l
 				var ctx context.Context
 				ctx, cancel = context.WithCancel(context.Background())
 				klog.InfoS("leaseHandler: Starting ModifyController", "leaseIdentity", leaseIdentity, "currentLeader", currentLeader)
-				go mc().Run(*workers, ctx)
+				running.Add(1)
+				go func() {
+					defer running.Done()
+					mc().Run(*workers, ctx)
+				}()
 			} else if currentLeader != leaseIdentity && cancel != nil {
 				klog.InfoS("leaseHandler: Stopping ModifyController", "leaseIdentity", leaseIdentity, "currentLeader", currentLeader)
 				cancel()
@@ -207,13 +472,32 @@ func leaseHandler(leaseIdentity string, mc func() controller.ModifyController, l
 			if cancel != nil {
 				cancel()
 			}
+			running.Wait()
 			klog.Fatalf("leaseHandler: No external-resizer lease update received within timeout period. Timeout: %v", *resyncPeriod)
 		}
 	}
 }
 
-func getDriverName(client csi.Client, timeout time.Duration) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-	return client.GetDriverName(ctx)
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// stringList is a flag that can be repeated, each value being a
+// comma-separated list.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, splitList(value)...)
+	return nil
 }
@@ -10,6 +10,7 @@ import (
 
 	v1 "k8s.io/api/coordination/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/golang/mock/gomock"
 )
@@ -252,3 +253,175 @@ func TestLeaseHandler_ChannelClosedWhileNotLeader(t *testing.T) {
 		t.Fatal("leaseHandler did not return")
 	}
 }
+
+func TestLeaderElectionLeaseName(t *testing.T) {
+	if got, want := leaderElectionLeaseName("ebs.csi.aws.com"), "volume-modifier-for-k8s-ebs-csi-aws-com"; got != want {
+		t.Fatalf("expected lease name %q, got %q", want, got)
+	}
+}
+
+func setLeaderElectionTimings(t *testing.T) {
+	leaseDuration, renewDeadline, retryPeriod := *leaderElectionLeaseDuration, *leaderElectionRenewDeadline, *leaderElectionRetryPeriod
+	*leaderElectionLeaseDuration = 2 * time.Second
+	*leaderElectionRenewDeadline = time.Second
+	*leaderElectionRetryPeriod = 100 * time.Millisecond
+	t.Cleanup(func() {
+		*leaderElectionLeaseDuration, *leaderElectionRenewDeadline, *leaderElectionRetryPeriod = leaseDuration, renewDeadline, retryPeriod
+	})
+}
+
+func TestRunWithLeaderElection_AcquiresLease(t *testing.T) {
+	setLeaderElectionTimings(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModifyController := controller.NewMockModifyController(ctrl)
+	signalChannel := make(chan struct{}, 1)
+	drainingChannel := make(chan struct{})
+	releaseChannel := make(chan struct{})
+	mockModifyController.EXPECT().Run(gomock.Eq(workerCount), gomock.Not(gomock.Nil())).Do(
+		func(_ int, ctx context.Context) {
+			signalChannel <- struct{}{}
+			<-ctx.Done()
+			close(drainingChannel)
+			<-releaseChannel
+		},
+	).Times(1)
+
+	kubeClient := fake.NewSimpleClientset()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runWithLeaderElection(ctx, kubeClient, "volume-modifier-for-k8s-ebs-csi-aws-com", "kube-system", "test-pod", func() controller.ModifyController { return mockModifyController }, nil)
+		close(done)
+	}()
+
+	select {
+	case <-signalChannel:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for Run to be called")
+	}
+	if holder := leaseHolder(t, kubeClient); holder != "test-pod" {
+		t.Fatalf("expected lease to be held by %q, got %q", "test-pod", holder)
+	}
+
+	// The lease must stay held while the controller drains.
+	cancel()
+	<-drainingChannel
+	time.Sleep(*leaderElectionRetryPeriod * 3)
+	if holder := leaseHolder(t, kubeClient); holder != "test-pod" {
+		t.Fatalf("expected lease to be held by %q while draining, got %q", "test-pod", holder)
+	}
+	close(releaseChannel)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runWithLeaderElection did not return after the controller stopped")
+	}
+	if holder := leaseHolder(t, kubeClient); holder != "" {
+		t.Fatalf("expected lease to be released, got holder %q", holder)
+	}
+}
+
+func TestRunWithLeaderElection_CancelledWhileNotLeader(t *testing.T) {
+	setLeaderElectionTimings(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModifyController := controller.NewMockModifyController(ctrl)
+	mockModifyController.EXPECT().Run(gomock.Eq(workerCount), gomock.Not(gomock.Nil())).Times(0)
+
+	kubeClient := fake.NewSimpleClientset(newHeldLease("other-pod"))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runWithLeaderElection(ctx, kubeClient, "volume-modifier-for-k8s-ebs-csi-aws-com", "kube-system", "test-pod", func() controller.ModifyController { return mockModifyController }, nil)
+		close(done)
+	}()
+
+	time.Sleep(*leaderElectionRetryPeriod * 3)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runWithLeaderElection did not return after context cancellation")
+	}
+}
+
+func leaseHolder(t *testing.T, kubeClient *fake.Clientset) string {
+	t.Helper()
+	lease, err := kubeClient.CoordinationV1().Leases("kube-system").Get(context.Background(), "volume-modifier-for-k8s-ebs-csi-aws-com", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get lease: %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func newHeldLease(holder string) *v1.Lease {
+	leaseDurationSeconds := int32(3600)
+	now := metav1.NewMicroTime(time.Now())
+	return &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volume-modifier-for-k8s-ebs-csi-aws-com",
+			Namespace: "kube-system",
+		},
+		Spec: v1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &leaseDurationSeconds,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+}
+
+func TestRunWithLeaderElection_LeaseHeldByOther(t *testing.T) {
+	setLeaderElectionTimings(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModifyController := controller.NewMockModifyController(ctrl)
+	mockModifyController.EXPECT().Run(gomock.Eq(workerCount), gomock.Not(gomock.Nil())).Times(0)
+
+	kubeClient := fake.NewSimpleClientset(newHeldLease("other-pod"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	runWithLeaderElection(ctx, kubeClient, "volume-modifier-for-k8s-ebs-csi-aws-com", "kube-system", "test-pod", func() controller.ModifyController { return mockModifyController }, nil)
+}
+
+func TestSplitList(t *testing.T) {
+	tests := map[string][]string{
+		"":                          nil,
+		"team":                      {"team"},
+		"team, cost-center,,":       {"team", "cost-center"},
+		"app.kubernetes.io/name, ,": {"app.kubernetes.io/name"},
+	}
+	for value, expected := range tests {
+		got := splitList(value)
+		if len(got) != len(expected) {
+			t.Fatalf("splitList(%q) = %v, want %v", value, got, expected)
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("splitList(%q) = %v, want %v", value, got, expected)
+			}
+		}
+	}
+}
+
+func TestStringList(t *testing.T) {
+	var addresses stringList
+	for _, value := range []string{"/run/ebs/socket", "/run/efs/socket, /run/fsx/socket"} {
+		if err := addresses.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := "/run/ebs/socket,/run/efs/socket,/run/fsx/socket"
+	if got := addresses.String(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
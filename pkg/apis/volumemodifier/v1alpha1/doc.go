@@ -0,0 +1,7 @@
+// Package v1alpha1 contains the v1alpha1 version of the volumemodifier.k8s.aws
+// API group, which lets users request volume modifications through typed
+// objects instead of PVC annotations.
+//
+// +k8s:deepcopy-gen=package
+// +groupName=volumemodifier.k8s.aws
+package v1alpha1
@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeModificationPlan applies the same modification to every PVC selected
+// by label, a few PVCs at a time.
+type VolumeModificationPlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeModificationPlanSpec   `json:"spec"`
+	Status VolumeModificationPlanStatus `json:"status,omitempty"`
+}
+
+// VolumeModificationPlanSpec is the desired state of the selected volumes.
+type VolumeModificationPlanSpec struct {
+	// DriverName is the name of the CSI driver whose volumes are modified.
+	// PVCs bound to volumes of other drivers are ignored.
+	DriverName string `json:"driverName"`
+
+	// Selector selects the PVCs to modify by label. An empty selector
+	// selects every PVC.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// NamespaceSelector restricts the PVCs to the namespaces matching it.
+	// An empty selector selects every namespace.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Parameters are the driver-specific parameters to apply to every
+	// selected volume, as in VolumeModificationSpec.
+	Parameters map[string]string `json:"parameters"`
+
+	// MaxConcurrency is the maximum number of volumes modified at the same
+	// time. Defaults to 1.
+	// +optional
+	MaxConcurrency int32 `json:"maxConcurrency,omitempty"`
+
+	// FailureThreshold is the number of failed volumes after which no new
+	// volume is modified. Defaults to 1.
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+
+	// Paused stops the modification of new volumes. Modifications already
+	// started are not interrupted.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// VolumeModificationPlanStatus is the observed state of a
+// VolumeModificationPlan.
+type VolumeModificationPlanStatus struct {
+	// ObservedGeneration is the generation of the spec the status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase summarizes the progress of the plan.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Pending, InProgress, Succeeded and Failed count the selected PVCs in
+	// each state.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+	// +optional
+	InProgress int32 `json:"inProgress,omitempty"`
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// Claims is the progress of every selected PVC.
+	// +optional
+	Claims []PlanClaimStatus `json:"claims,omitempty"`
+}
+
+// PlanClaimStatus is the progress of the modification of one PVC.
+type PlanClaimStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// State is one of the PlanClaim* constants.
+	State string `json:"state"`
+	// Message explains a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Phases of a VolumeModificationPlan.
+const (
+	// PlanPhaseRunning is used while selected volumes remain to be modified.
+	PlanPhaseRunning = "Running"
+	// PlanPhasePaused is used while spec.paused is set.
+	PlanPhasePaused = "Paused"
+	// PlanPhaseCompleted is used once every selected volume is modified.
+	PlanPhaseCompleted = "Completed"
+	// PlanPhaseFailed is used once the failure threshold has been reached.
+	PlanPhaseFailed = "Failed"
+)
+
+// States of the PVCs of a VolumeModificationPlan.
+const (
+	PlanClaimPending    = "Pending"
+	PlanClaimInProgress = "InProgress"
+	PlanClaimSucceeded  = "Succeeded"
+	PlanClaimFailed     = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeModificationPlanList is a list of VolumeModificationPlan objects.
+type VolumeModificationPlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeModificationPlan `json:"items"`
+}
@@ -0,0 +1,51 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeModificationQuota caps the sum of integer parameters, such as
+// provisioned IOPS or throughput, over the volumes of a driver bound to PVCs
+// of its namespace.
+type VolumeModificationQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeModificationQuotaSpec   `json:"spec"`
+	Status VolumeModificationQuotaStatus `json:"status,omitempty"`
+}
+
+// VolumeModificationQuotaSpec is the budget of the namespace.
+type VolumeModificationQuotaSpec struct {
+	// DriverName is the name of the CSI driver whose volumes are counted.
+	DriverName string `json:"driverName"`
+
+	// Hard is the maximum sum of each driver-specific parameter, e.g.
+	// {"iops": 100000}. Modifications that would exceed it are refused.
+	Hard map[string]int64 `json:"hard"`
+}
+
+// VolumeModificationQuotaStatus is the observed usage of the namespace.
+type VolumeModificationQuotaStatus struct {
+	// ObservedGeneration is the generation of the spec the status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Used is the current sum of each parameter of Hard, as last applied to
+	// the volumes.
+	// +optional
+	Used map[string]int64 `json:"used,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeModificationQuotaList is a list of VolumeModificationQuota objects.
+type VolumeModificationQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeModificationQuota `json:"items"`
+}
@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeModification requests the modification of the volume bound to a
+// PersistentVolumeClaim in the same namespace.
+type VolumeModification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeModificationSpec   `json:"spec"`
+	Status VolumeModificationStatus `json:"status,omitempty"`
+}
+
+// VolumeModificationSpec is the desired state of a volume.
+type VolumeModificationSpec struct {
+	// PersistentVolumeClaimName is the name of the PVC whose volume is modified.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+
+	// Parameters are the driver-specific parameters to apply, e.g. "type",
+	// "iops" or "throughput". They are the same keys as the ones used in
+	// "<driver-name>/<key>" PVC annotations.
+	Parameters map[string]string `json:"parameters"`
+}
+
+// VolumeModificationStatus is the observed state of a VolumeModification.
+type VolumeModificationStatus struct {
+	// ObservedGeneration is the generation of the spec the status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// OperationID identifies the modification the driver is still applying.
+	// +optional
+	OperationID string `json:"operationID,omitempty"`
+
+	// Conditions describe the progress of the modification.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// ConditionApplied reports whether the parameters of the spec have been
+	// applied to the volume.
+	ConditionApplied = "Applied"
+
+	// ReasonPending is used while the PVC is not bound to a volume yet.
+	ReasonPending = "Pending"
+	// ReasonInProgress is used while the driver applies the modification.
+	ReasonInProgress = "InProgress"
+	// ReasonSucceeded is used once the modification has been applied.
+	ReasonSucceeded = "Succeeded"
+	// ReasonFailed is used when the driver failed to apply the modification.
+	ReasonFailed = "Failed"
+	// ReasonRejected is used when the modification is refused before the
+	// driver is called, e.g. because of invalid parameters.
+	ReasonRejected = "Rejected"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeModificationList is a list of VolumeModification objects.
+type VolumeModificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeModification `json:"items"`
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+)
+
+type ParameterType int
+
+const (
+	ParameterTypeString ParameterType = iota
+	ParameterTypeInteger
+	ParameterTypeBoolean
+)
+
+func (t ParameterType) String() string {
+	switch t {
+	case ParameterTypeInteger:
+		return "integer"
+	case ParameterTypeBoolean:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// ParameterSchema describes a modification parameter accepted by the driver.
+type ParameterSchema struct {
+	Name          string
+	Type          ParameterType
+	AllowedValues []string
+	Min           *int64
+	Max           *int64
+	Online        bool
+}
+
+// ModificationCapability is the modification schema advertised by the driver.
+type ModificationCapability struct {
+	// Parameters is keyed by parameter name. An empty map means the driver
+	// did not advertise a schema and accepts any parameter.
+	Parameters map[string]ParameterSchema
+
+	// Cooldown is the minimum time the driver requires between two
+	// modifications of the same volume. Zero means no restriction.
+	Cooldown time.Duration
+}
+
+func newModificationCapability(resp *modifyrpc.GetCSIDriverModificationCapabilityResponse) *ModificationCapability {
+	capability := &ModificationCapability{
+		Parameters: make(map[string]ParameterSchema),
+		Cooldown:   resp.GetModificationCooldown().AsDuration(),
+	}
+	for _, p := range resp.GetParameters() {
+		schema := ParameterSchema{
+			Name:          p.GetName(),
+			AllowedValues: p.GetAllowedValues(),
+			Min:           p.MinValue,
+			Max:           p.MaxValue,
+			Online:        p.GetOnline(),
+		}
+		switch p.GetType() {
+		case modifyrpc.ParameterSchema_INTEGER:
+			schema.Type = ParameterTypeInteger
+		case modifyrpc.ParameterSchema_BOOLEAN:
+			schema.Type = ParameterTypeBoolean
+		default:
+			schema.Type = ParameterTypeString
+		}
+		capability.Parameters[schema.Name] = schema
+	}
+	return capability
+}
+
+// Validate checks params against the advertised schema and reports every
+// violation in a single error.
+func (c *ModificationCapability) Validate(params map[string]string) error {
+	if c == nil || len(c.Parameters) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var violations []string
+	for _, key := range keys {
+		schema, ok := c.Parameters[key]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("parameter %q is not supported by the driver", key))
+			continue
+		}
+		if err := schema.validate(params[key]); err != nil {
+			violations = append(violations, fmt.Sprintf("parameter %q: %v", key, err))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func (s ParameterSchema) validate(value string) error {
+	switch s.Type {
+	case ParameterTypeInteger:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("value %q is not an %s", value, s.Type)
+		}
+		if s.Min != nil && n < *s.Min {
+			return fmt.Errorf("value %d is below the minimum of %d", n, *s.Min)
+		}
+		if s.Max != nil && n > *s.Max {
+			return fmt.Errorf("value %d is above the maximum of %d", n, *s.Max)
+		}
+	case ParameterTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value %q is not a %s", value, s.Type)
+		}
+	}
+
+	if len(s.AllowedValues) > 0 && !slices.Contains(s.AllowedValues, value) {
+		return fmt.Errorf("value %q is not one of %s", value, strings.Join(s.AllowedValues, ", "))
+	}
+	return nil
+}
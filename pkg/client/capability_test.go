@@ -0,0 +1,140 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestCapability() *ModificationCapability {
+	return newModificationCapability(&modifyrpc.GetCSIDriverModificationCapabilityResponse{
+		Parameters: []*modifyrpc.ParameterSchema{
+			{
+				Name:          "type",
+				AllowedValues: []string{"gp2", "gp3", "io2"},
+				Online:        true,
+			},
+			{
+				Name:     "iops",
+				Type:     modifyrpc.ParameterSchema_INTEGER,
+				MinValue: proto.Int64(3000),
+				MaxValue: proto.Int64(64000),
+				Online:   true,
+			},
+			{
+				Name: "encrypted",
+				Type: modifyrpc.ParameterSchema_BOOLEAN,
+			},
+		},
+		ModificationCooldown: durationpb.New(6 * time.Hour),
+	})
+}
+
+func TestNewModificationCapability(t *testing.T) {
+	capability := newTestCapability()
+	if len(capability.Parameters) != 3 {
+		t.Fatalf("expected 3 parameters, got %d", len(capability.Parameters))
+	}
+	iops := capability.Parameters["iops"]
+	if iops.Type != ParameterTypeInteger || iops.Min == nil || *iops.Min != 3000 || iops.Max == nil || *iops.Max != 64000 || !iops.Online {
+		t.Fatalf("unexpected iops schema: %+v", iops)
+	}
+	if encrypted := capability.Parameters["encrypted"]; encrypted.Type != ParameterTypeBoolean || encrypted.Online {
+		t.Fatalf("unexpected encrypted schema: %+v", encrypted)
+	}
+	if capability.Cooldown != 6*time.Hour {
+		t.Fatalf("unexpected cooldown: %v", capability.Cooldown)
+	}
+}
+
+func TestNewModificationCapability_NoCooldown(t *testing.T) {
+	capability := newModificationCapability(&modifyrpc.GetCSIDriverModificationCapabilityResponse{})
+	if capability.Cooldown != 0 {
+		t.Fatalf("expected no cooldown, got %v", capability.Cooldown)
+	}
+}
+
+func TestModificationCapabilityValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		capability  *ModificationCapability
+		params      map[string]string
+		expectedErr string
+	}{
+		{
+			name:       "nil capability accepts everything",
+			capability: nil,
+			params:     map[string]string{"iopss": "fast"},
+		},
+		{
+			name:       "empty schema accepts everything",
+			capability: &ModificationCapability{},
+			params:     map[string]string{"iopss": "fast"},
+		},
+		{
+			name:       "valid parameters",
+			capability: newTestCapability(),
+			params:     map[string]string{"type": "io2", "iops": "16000", "encrypted": "true"},
+		},
+		{
+			name:        "unknown parameter",
+			capability:  newTestCapability(),
+			params:      map[string]string{"iopss": "16000"},
+			expectedErr: `parameter "iopss" is not supported by the driver`,
+		},
+		{
+			name:        "value not allowed",
+			capability:  newTestCapability(),
+			params:      map[string]string{"type": "st1"},
+			expectedErr: `parameter "type": value "st1" is not one of gp2, gp3, io2`,
+		},
+		{
+			name:        "not an integer",
+			capability:  newTestCapability(),
+			params:      map[string]string{"iops": "fast"},
+			expectedErr: `parameter "iops": value "fast" is not an integer`,
+		},
+		{
+			name:        "below minimum",
+			capability:  newTestCapability(),
+			params:      map[string]string{"iops": "100"},
+			expectedErr: `parameter "iops": value 100 is below the minimum of 3000`,
+		},
+		{
+			name:        "above maximum",
+			capability:  newTestCapability(),
+			params:      map[string]string{"iops": "100000"},
+			expectedErr: `parameter "iops": value 100000 is above the maximum of 64000`,
+		},
+		{
+			name:        "not a boolean",
+			capability:  newTestCapability(),
+			params:      map[string]string{"encrypted": "maybe"},
+			expectedErr: `parameter "encrypted": value "maybe" is not a boolean`,
+		},
+		{
+			name:        "all violations are reported",
+			capability:  newTestCapability(),
+			params:      map[string]string{"iops": "fast", "type": "st1"},
+			expectedErr: `parameter "iops": value "fast" is not an integer; parameter "type": value "st1" is not one of gp2, gp3, io2`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.capability.Validate(tc.params)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/kubernetes-csi/csi-lib-utils/connection"
@@ -17,23 +18,80 @@ import (
 type Client interface {
 	GetDriverName(context.Context) (string, error)
 
+	// Probe checks that the driver is reachable and reports itself ready
+	// through the CSI Identity service.
+	Probe(context.Context) error
+
 	SupportsVolumeModification(context.Context) error
 
-	Modify(ctx context.Context, volumeID string, params, reqContext map[string]string) error
+	// GetModificationCapability returns the parameter schema advertised by
+	// the driver. The first successful response is cached.
+	GetModificationCapability(context.Context) (*ModificationCapability, error)
+
+	// Modify requests the modification of a volume. A non-empty operation ID
+	// is returned when the driver is still applying the modification, in
+	// which case GetModificationStatus must be polled until it completes.
+	// secrets may be nil and must not be logged. A failure reported by the
+	// driver is returned as a *ModificationError.
+	Modify(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) (string, error)
+
+	GetModificationStatus(ctx context.Context, volumeID, operationID string) (*ModificationStatus, error)
+
+	// Validate asks the driver whether it would accept the modification
+	// without changing the volume. A rejection is reported as a
+	// *ValidationError.
+	Validate(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) error
+
+	// GetVolumeProperties returns the current attributes of the volume as
+	// reported by the driver.
+	GetVolumeProperties(ctx context.Context, volumeID string) (map[string]string, error)
 
 	CloseConnection()
 }
 
+type ModificationState int
+
+const (
+	ModificationInProgress ModificationState = iota
+	ModificationCompleted
+	ModificationFailed
+)
+
+// ValidationError is returned by Validate when the driver would reject
+// the proposed modification.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("driver rejected the modification: %s", e.Message)
+}
+
+// ModificationStatus is the driver-reported status of an asynchronous modification.
+type ModificationStatus struct {
+	State   ModificationState
+	Message string
+}
+
+// New connects to the CSI driver at addr. The process exits when the
+// connection is lost; use NewResilient to reconnect instead.
 func New(addr string, timeout time.Duration, metricsmanager metrics.CSIMetricsManager) (Client, error) {
+	return connect(addr, timeout, metricsmanager, connection.ExitOnConnectionLoss())
+}
+
+// connect dials addr and probes the driver until it is ready or timeout
+// expires. onConnectionLoss is called when the connection is lost.
+func connect(addr string, timeout time.Duration, metricsmanager metrics.CSIMetricsManager, onConnectionLoss func(context.Context) bool) (*client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	conn, err := connection.Connect(ctx, addr, metricsmanager, connection.OnConnectionLoss(connection.ExitOnConnectionLoss()))
+	conn, err := connection.Connect(ctx, addr, metricsmanager, connection.OnConnectionLoss(onConnectionLoss))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to CSI driver: %w", err)
 	}
 
 	err = rpc.ProbeForever(ctx, conn, timeout)
 	if err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed probing CSI driver: %w", err)
 	}
 
@@ -44,31 +102,123 @@ func New(addr string, timeout time.Duration, metricsmanager metrics.CSIMetricsMa
 
 type client struct {
 	conn *grpc.ClientConn
+
+	capabilityMu sync.Mutex
+	capability   *ModificationCapability
 }
 
 func (c *client) GetDriverName(ctx context.Context) (string, error) {
 	return rpc.GetDriverName(ctx, c.conn)
 }
 
+func (c *client) Probe(ctx context.Context) error {
+	ready, err := rpc.Probe(ctx, c.conn)
+	if err != nil {
+		return fmt.Errorf("failed probing CSI driver: %w", err)
+	}
+	if !ready {
+		return fmt.Errorf("CSI driver is not ready")
+	}
+	return nil
+}
+
 func (c *client) SupportsVolumeModification(ctx context.Context) error {
+	_, err := c.GetModificationCapability(ctx)
+	return err
+}
+
+func (c *client) GetModificationCapability(ctx context.Context) (*ModificationCapability, error) {
+	c.capabilityMu.Lock()
+	defer c.capabilityMu.Unlock()
+	if c.capability != nil {
+		return c.capability, nil
+	}
+
 	cc := modifyrpc.NewModifyClient(c.conn)
 	req := &modifyrpc.GetCSIDriverModificationCapabilityRequest{}
-	_, err := cc.GetCSIDriverModificationCapability(ctx, req)
-	return err
+	resp, err := cc.GetCSIDriverModificationCapability(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.capability = newModificationCapability(resp)
+	klog.V(4).InfoS("Driver modification capability", "parameters", len(c.capability.Parameters))
+	return c.capability, nil
 }
 
-func (c *client) Modify(ctx context.Context, volumeID string, params, reqContext map[string]string) error {
+func (c *client) Modify(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) (string, error) {
 	cc := modifyrpc.NewModifyClient(c.conn)
 	req := &modifyrpc.ModifyVolumePropertiesRequest{
 		Name:       volumeID,
 		Parameters: params,
 		Context:    reqContext,
+		Secrets:    secrets,
+	}
+	resp, err := cc.ModifyVolumeProperties(ctx, req)
+	if err != nil {
+		return "", newModificationError(err)
 	}
-	_, err := cc.ModifyVolumeProperties(ctx, req)
-	if err == nil {
-		klog.V(4).InfoS("Volume modification completed", "volumeID", volumeID)
+	if resp.GetOperationId() != "" {
+		klog.V(4).InfoS("Volume modification accepted", "volumeID", volumeID, "operationID", resp.GetOperationId())
+		return resp.GetOperationId(), nil
 	}
-	return err
+	klog.V(4).InfoS("Volume modification completed", "volumeID", volumeID)
+	return "", nil
+}
+
+func (c *client) GetModificationStatus(ctx context.Context, volumeID, operationID string) (*ModificationStatus, error) {
+	cc := modifyrpc.NewModifyClient(c.conn)
+	req := &modifyrpc.GetModificationStatusRequest{
+		Name:        volumeID,
+		OperationId: operationID,
+	}
+	resp, err := cc.GetModificationStatus(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ModificationStatus{Message: resp.GetMessage()}
+	switch resp.GetState() {
+	case modifyrpc.GetModificationStatusResponse_IN_PROGRESS:
+		status.State = ModificationInProgress
+	case modifyrpc.GetModificationStatusResponse_COMPLETED:
+		status.State = ModificationCompleted
+	case modifyrpc.GetModificationStatusResponse_FAILED:
+		status.State = ModificationFailed
+	default:
+		return nil, fmt.Errorf("driver returned unknown state %v for operation %s on volume %s", resp.GetState(), operationID, volumeID)
+	}
+	return status, nil
+}
+
+func (c *client) Validate(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) error {
+	cc := modifyrpc.NewModifyClient(c.conn)
+	req := &modifyrpc.ValidateVolumeModificationRequest{
+		Name:       volumeID,
+		Parameters: params,
+		Context:    reqContext,
+		Secrets:    secrets,
+	}
+	resp, err := cc.ValidateVolumeModification(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.GetSupported() {
+		return &ValidationError{Message: resp.GetMessage()}
+	}
+	klog.V(4).InfoS("Volume modification validated", "volumeID", volumeID)
+	return nil
+}
+
+func (c *client) GetVolumeProperties(ctx context.Context, volumeID string) (map[string]string, error) {
+	cc := modifyrpc.NewModifyClient(c.conn)
+	req := &modifyrpc.GetVolumePropertiesRequest{
+		Name: volumeID,
+	}
+	resp, err := cc.GetVolumeProperties(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetParameters(), nil
 }
 
 func (c *client) CloseConnection() {
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/protobuf/proto"
+
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+)
+
+// The gRPC request logging of csi-lib-utils strips the fields marked with
+// the csi_secret option.
+func TestSecretsMarkedAsSecret(t *testing.T) {
+	for _, msg := range []proto.Message{
+		&modifyrpc.ModifyVolumePropertiesRequest{},
+		&modifyrpc.ValidateVolumeModificationRequest{},
+	} {
+		name := msg.ProtoReflect().Descriptor().Name()
+		field := msg.ProtoReflect().Descriptor().Fields().ByName("secrets")
+		if field == nil {
+			t.Fatalf("%s has no secrets field", name)
+		}
+		if secret, _ := proto.GetExtension(field.Options(), csi.E_CsiSecret).(bool); !secret {
+			t.Fatalf("expected the secrets field of %s to be marked with the csi_secret option", name)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 )
 
 func NewFakeClient(
@@ -28,12 +29,38 @@ type FakeClient struct {
 	volumeID                   string
 	params                     map[string]string
 	reqContext                 map[string]string
+	secrets                    map[string]string
+	operationID                string
+	operationStatuses          []ModificationStatus
+	statusCalled               int
+	capability                 *ModificationCapability
+	validateCalled             int
+	validationError            string
+	volumeProperties           map[string]string
+	modifyDelay                time.Duration
+	probeError                 error
+	modifyError                error
+	statusError                error
 }
 
 func (f *FakeClient) GetDriverName(context.Context) (string, error) {
 	return f.name, nil
 }
 
+func (f *FakeClient) Probe(context.Context) error {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	return f.probeError
+}
+
+// SetProbeError sets the error returned by Probe. A nil error makes the
+// driver report itself ready.
+func (f *FakeClient) SetProbeError(err error) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.probeError = err
+}
+
 func (f *FakeClient) SupportsVolumeModification(context.Context) error {
 	if !f.driverSupportsModification {
 		return fmt.Errorf("does not support modification")
@@ -41,23 +68,143 @@ func (f *FakeClient) SupportsVolumeModification(context.Context) error {
 	return nil
 }
 
-func (f *FakeClient) Modify(ctx context.Context, volumeID string, params, reqContext map[string]string) error {
+func (f *FakeClient) GetModificationCapability(ctx context.Context) (*ModificationCapability, error) {
+	if err := f.SupportsVolumeModification(ctx); err != nil {
+		return nil, err
+	}
+	if f.capability == nil {
+		return &ModificationCapability{}, nil
+	}
+	return f.capability, nil
+}
+
+// SetModificationCapability sets the schema returned by GetModificationCapability.
+func (f *FakeClient) SetModificationCapability(capability *ModificationCapability) {
+	f.capability = capability
+}
+
+func (f *FakeClient) Modify(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) (string, error) {
 	f.modifyCalledMu.Lock()
-	defer f.modifyCalledMu.Unlock()
 	f.modifyCalled++
 	f.volumeID = volumeID
 	f.params = params
 	f.reqContext = reqContext
-	if f.modificationShouldFail {
-		return fmt.Errorf("modification failed")
+	f.secrets = secrets
+	delay, shouldFail, operationID, modifyErr := f.modifyDelay, f.modificationShouldFail, f.operationID, f.modifyError
+	f.modifyCalledMu.Unlock()
+
+	time.Sleep(delay)
+	if modifyErr != nil {
+		return "", newModificationError(modifyErr)
+	}
+	if shouldFail {
+		return "", fmt.Errorf("modification failed")
+	}
+	return operationID, nil
+}
+
+// SetModifyDelay makes Modify take the given time to return, like a driver
+// that applies the change synchronously.
+func (f *FakeClient) SetModifyDelay(delay time.Duration) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.modifyDelay = delay
+}
+
+// SetModifyError makes Modify fail with err, which is wrapped in a
+// *ModificationError when it carries a gRPC status, as the real client does.
+// A nil error lets Modify succeed again.
+func (f *FakeClient) SetModifyError(err error) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.modifyError = err
+}
+
+func (f *FakeClient) GetModificationStatus(ctx context.Context, volumeID, operationID string) (*ModificationStatus, error) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	if operationID != f.operationID || len(f.operationStatuses) == 0 {
+		return nil, fmt.Errorf("unknown operation %s", operationID)
+	}
+	if f.statusError != nil {
+		f.statusCalled++
+		return nil, f.statusError
+	}
+	// Walk through the configured statuses, repeating the last one.
+	i := f.statusCalled
+	if i >= len(f.operationStatuses) {
+		i = len(f.operationStatuses) - 1
+	}
+	f.statusCalled++
+	status := f.operationStatuses[i]
+	return &status, nil
+}
+
+// SetAsyncModification makes Modify return operationID and successive
+// GetModificationStatus calls return statuses in order.
+func (f *FakeClient) SetAsyncModification(operationID string, statuses ...ModificationStatus) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.operationID = operationID
+	f.operationStatuses = statuses
+	f.statusCalled = 0
+}
+
+// SetStatusError makes GetModificationStatus fail with err. A nil error lets
+// it return the configured statuses again.
+func (f *FakeClient) SetStatusError(err error) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.statusError = err
+}
+
+func (f *FakeClient) Validate(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) error {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.validateCalled++
+	f.volumeID = volumeID
+	f.params = params
+	f.reqContext = reqContext
+	f.secrets = secrets
+	if f.validationError != "" {
+		return &ValidationError{Message: f.validationError}
 	}
 	return nil
 }
 
+// SetValidationError makes Validate reject every modification with message.
+func (f *FakeClient) SetValidationError(message string) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.validationError = message
+}
+
+func (f *FakeClient) GetVolumeProperties(ctx context.Context, volumeID string) (map[string]string, error) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	return f.volumeProperties, nil
+}
+
+// SetVolumeProperties sets the properties returned by GetVolumeProperties.
+func (f *FakeClient) SetVolumeProperties(properties map[string]string) {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	f.volumeProperties = properties
+}
+
 func (f *FakeClient) CloseConnection() {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
 	f.closed = true
 }
 
+// IsClosed reports whether CloseConnection was called.
+func (f *FakeClient) IsClosed() bool {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	return f.closed
+}
+
 func (f *FakeClient) GetVolumeName() string {
 	return f.volumeID
 }
@@ -70,8 +217,27 @@ func (f *FakeClient) GetReqContext() map[string]string {
 	return f.reqContext
 }
 
+// GetSecrets returns the secrets passed to the last Modify or Validate call.
+func (f *FakeClient) GetSecrets() map[string]string {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	return f.secrets
+}
+
 func (f *FakeClient) GetModifyCallCount() int {
 	f.modifyCalledMu.Lock()
 	defer f.modifyCalledMu.Unlock()
 	return f.modifyCalled
 }
+
+func (f *FakeClient) GetStatusCallCount() int {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	return f.statusCalled
+}
+
+func (f *FakeClient) GetValidateCallCount() int {
+	f.modifyCalledMu.Lock()
+	defer f.modifyCalledMu.Unlock()
+	return f.validateCalled
+}
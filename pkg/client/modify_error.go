@@ -0,0 +1,75 @@
+package client
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ModificationError is returned by Modify when the driver fails the
+// modification with a gRPC status.
+type ModificationError struct {
+	Code    codes.Code
+	Message string
+	// RetryAfter is the delay the driver asked to wait before retrying,
+	// from the google.rpc.RetryInfo details of the status, or zero.
+	RetryAfter time.Duration
+
+	status *status.Status
+}
+
+func (e *ModificationError) Error() string {
+	return e.status.Err().Error()
+}
+
+// GRPCStatus returns the status returned by the driver, so that
+// status.Code and status.FromError see through the error.
+func (e *ModificationError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// Terminal reports whether retrying the same modification cannot succeed,
+// e.g. because the driver rejected its parameters. Errors carrying a
+// RetryInfo are never terminal.
+func (e *ModificationError) Terminal() bool {
+	if e.RetryAfter > 0 {
+		return false
+	}
+	switch e.Code {
+	case codes.InvalidArgument,
+		codes.NotFound,
+		codes.AlreadyExists,
+		codes.PermissionDenied,
+		codes.FailedPrecondition,
+		codes.OutOfRange,
+		codes.Unimplemented,
+		codes.Unauthenticated:
+		return true
+	default:
+		// Canceled, Unknown, DeadlineExceeded, ResourceExhausted, Aborted,
+		// Internal, Unavailable and DataLoss may be transient.
+		return false
+	}
+}
+
+// newModificationError wraps the gRPC status of err in a
+// *ModificationError. Errors without a status are returned as is.
+func newModificationError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() == codes.OK {
+		return err
+	}
+	modErr := &ModificationError{
+		Code:    s.Code(),
+		Message: s.Message(),
+		status:  s,
+	}
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			modErr.RetryAfter = info.GetRetryDelay().AsDuration()
+		}
+	}
+	return modErr
+}
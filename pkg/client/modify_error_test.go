@@ -0,0 +1,88 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func withRetryInfo(t *testing.T, code codes.Code, delay time.Duration) error {
+	t.Helper()
+	s, err := status.New(code, "request rate exceeded").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.Err()
+}
+
+func TestNewModificationError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		terminal   bool
+		retryAfter time.Duration
+	}{
+		{
+			name:     "invalid argument",
+			err:      status.Error(codes.InvalidArgument, "iops must be at least 3000"),
+			terminal: true,
+		},
+		{
+			name:     "failed precondition",
+			err:      status.Error(codes.FailedPrecondition, "volume is not in the available state"),
+			terminal: true,
+		},
+		{
+			name: "internal",
+			err:  status.Error(codes.Internal, "could not modify volume"),
+		},
+		{
+			name: "resource exhausted",
+			err:  status.Error(codes.ResourceExhausted, "request rate exceeded"),
+		},
+		{
+			name:       "retry info",
+			err:        withRetryInfo(t, codes.ResourceExhausted, 30*time.Second),
+			retryAfter: 30 * time.Second,
+		},
+		{
+			name:       "retry info overrides terminal code",
+			err:        withRetryInfo(t, codes.FailedPrecondition, time.Minute),
+			retryAfter: time.Minute,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newModificationError(tc.err)
+			var modErr *ModificationError
+			if !errors.As(err, &modErr) {
+				t.Fatalf("expected *ModificationError, got %T", err)
+			}
+			if modErr.Terminal() != tc.terminal {
+				t.Errorf("expected terminal %t, got %t", tc.terminal, modErr.Terminal())
+			}
+			if modErr.RetryAfter != tc.retryAfter {
+				t.Errorf("expected retry after %s, got %s", tc.retryAfter, modErr.RetryAfter)
+			}
+			if status.Code(err) != status.Code(tc.err) {
+				t.Errorf("expected code %s to be preserved, got %s", status.Code(tc.err), status.Code(err))
+			}
+			if err.Error() != tc.err.Error() {
+				t.Errorf("expected message %q, got %q", tc.err.Error(), err.Error())
+			}
+		})
+	}
+}
+
+func TestNewModificationError_WithoutStatus(t *testing.T) {
+	err := errors.New("volume ID is empty")
+	var modErr *ModificationError
+	if errors.As(newModificationError(err), &modErr) {
+		t.Fatal("expected errors without a gRPC status not to be wrapped")
+	}
+}
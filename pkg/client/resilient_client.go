@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// ErrDriverUnavailable is returned by the calls of a ResilientClient while it
+// reconnects to the driver.
+var ErrDriverUnavailable = status.Error(codes.Unavailable, "connection to the CSI driver was lost, reconnecting")
+
+// defaultReconnectBackoff spaces the attempts to reconnect to the driver.
+var defaultReconnectBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    1 << 30,
+	Cap:      time.Minute,
+}
+
+// ResilientClient is a Client that reconnects to the driver when the
+// connection is lost, e.g. because the driver container restarted, instead
+// of exiting. While it reconnects, every call fails with ErrDriverUnavailable
+// and Available reports false. Only the loss of the connection reported by
+// csi-lib-utils makes it reconnect: errors returned by the driver, including
+// Unavailable, leave the connection to the other calls.
+type ResilientClient struct {
+	// dial connects to the driver and probes it until it is ready.
+	dial    func(onConnectionLoss func(context.Context) bool) (Client, error)
+	timeout time.Duration
+	backoff wait.Backoff
+
+	mu     sync.RWMutex
+	client Client
+	// available is closed once the driver is connected and supports volume
+	// modification.
+	available chan struct{}
+	closed    bool
+}
+
+var _ Client = &ResilientClient{}
+
+// NewResilient connects to the CSI driver at addr like New, but reconnects
+// with backoff when the connection is lost.
+func NewResilient(addr string, timeout time.Duration, metricsmanager metrics.CSIMetricsManager) (*ResilientClient, error) {
+	return newResilientClient(func(onConnectionLoss func(context.Context) bool) (Client, error) {
+		return connect(addr, timeout, metricsmanager, onConnectionLoss)
+	}, timeout, defaultReconnectBackoff)
+}
+
+func newResilientClient(dial func(onConnectionLoss func(context.Context) bool) (Client, error), timeout time.Duration, backoff wait.Backoff) (*ResilientClient, error) {
+	c := &ResilientClient{
+		dial:      dial,
+		timeout:   timeout,
+		backoff:   backoff,
+		available: make(chan struct{}),
+	}
+	client, err := c.dialClient()
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	close(c.available)
+	return c, nil
+}
+
+// Available reports whether the driver is connected.
+func (c *ResilientClient) Available() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client != nil
+}
+
+// WaitAvailable blocks until the driver is connected or ctx is done.
+func (c *ResilientClient) WaitAvailable(ctx context.Context) error {
+	c.mu.RLock()
+	available := c.available
+	c.mu.RUnlock()
+	select {
+	case <-available:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dialClient connects to the driver. The connection loss reported by
+// csi-lib-utils for the returned client makes it reconnect.
+func (c *ResilientClient) dialClient() (Client, error) {
+	var mu sync.Mutex
+	var dialed Client
+	client, err := c.dial(func(context.Context) bool {
+		mu.Lock()
+		lost := dialed
+		mu.Unlock()
+		if lost != nil {
+			c.connectionLost(lost)
+		}
+		// The connection is replaced by a new one.
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	dialed = client
+	mu.Unlock()
+	return client, nil
+}
+
+// connectionLost starts reconnecting unless lost is no longer the current
+// client, e.g. because several calls failed at once.
+func (c *ResilientClient) connectionLost(lost Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.client == nil || lost != c.client {
+		return
+	}
+	klog.InfoS("Lost connection to CSI driver, reconnecting")
+	c.client.CloseConnection()
+	c.client = nil
+	c.available = make(chan struct{})
+	go c.reconnect()
+}
+
+// reconnect dials the driver with backoff until it is ready and supports
+// volume modification again.
+func (c *ResilientClient) reconnect() {
+	backoff := c.backoff
+	for {
+		client, err := c.dialClient()
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			err = client.SupportsVolumeModification(ctx)
+			cancel()
+			if err != nil {
+				client.CloseConnection()
+			}
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			if err == nil {
+				client.CloseConnection()
+			}
+			return
+		}
+		if err == nil {
+			c.client = client
+			close(c.available)
+			c.mu.Unlock()
+			klog.InfoS("Reconnected to CSI driver")
+			return
+		}
+		c.mu.Unlock()
+
+		delay := backoff.Step()
+		klog.V(2).InfoS("Cannot reconnect to CSI driver, retrying", "err", err, "delay", delay)
+		time.Sleep(delay)
+	}
+}
+
+// current returns the connected client or ErrDriverUnavailable.
+func (c *ResilientClient) current() (Client, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.client == nil {
+		return nil, ErrDriverUnavailable
+	}
+	return c.client, nil
+}
+
+func (c *ResilientClient) GetDriverName(ctx context.Context) (string, error) {
+	client, err := c.current()
+	if err != nil {
+		return "", err
+	}
+	return client.GetDriverName(ctx)
+}
+
+func (c *ResilientClient) Probe(ctx context.Context) error {
+	client, err := c.current()
+	if err != nil {
+		return err
+	}
+	return client.Probe(ctx)
+}
+
+func (c *ResilientClient) SupportsVolumeModification(ctx context.Context) error {
+	client, err := c.current()
+	if err != nil {
+		return err
+	}
+	return client.SupportsVolumeModification(ctx)
+}
+
+func (c *ResilientClient) GetModificationCapability(ctx context.Context) (*ModificationCapability, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetModificationCapability(ctx)
+}
+
+func (c *ResilientClient) Modify(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) (string, error) {
+	client, err := c.current()
+	if err != nil {
+		return "", err
+	}
+	return client.Modify(ctx, volumeID, params, reqContext, secrets)
+}
+
+func (c *ResilientClient) GetModificationStatus(ctx context.Context, volumeID, operationID string) (*ModificationStatus, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetModificationStatus(ctx, volumeID, operationID)
+}
+
+func (c *ResilientClient) Validate(ctx context.Context, volumeID string, params, reqContext, secrets map[string]string) error {
+	client, err := c.current()
+	if err != nil {
+		return err
+	}
+	return client.Validate(ctx, volumeID, params, reqContext, secrets)
+}
+
+func (c *ResilientClient) GetVolumeProperties(ctx context.Context, volumeID string) (map[string]string, error) {
+	client, err := c.current()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetVolumeProperties(ctx, volumeID)
+}
+
+func (c *ResilientClient) CloseConnection() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.client != nil {
+		c.client.CloseConnection()
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// testDialer hands out the given clients in order, failing the dial for nil
+// entries, and records the connection loss handlers. Dials after the first
+// one wait for release to be closed.
+type testDialer struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	clients  []*FakeClient
+	handlers []func(context.Context) bool
+	dials    int
+}
+
+func (d *testDialer) dial(onConnectionLoss func(context.Context) bool) (Client, error) {
+	d.mu.Lock()
+	first := d.dials == 0
+	d.dials++
+	d.mu.Unlock()
+	if !first {
+		<-d.release
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.clients) == 0 {
+		return nil, errors.New("connection refused")
+	}
+	client := d.clients[0]
+	d.clients = d.clients[1:]
+	if client == nil {
+		return nil, errors.New("connection refused")
+	}
+	d.handlers = append(d.handlers, onConnectionLoss)
+	return client, nil
+}
+
+func (d *testDialer) handler(i int) func(context.Context) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.handlers[i]
+}
+
+func newTestResilientClient(t *testing.T, clients ...*FakeClient) (*ResilientClient, *testDialer) {
+	t.Helper()
+	dialer := &testDialer{clients: clients, release: make(chan struct{})}
+	c, err := newResilientClient(dialer.dial, time.Second, wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.CloseConnection)
+	return c, dialer
+}
+
+func waitAvailable(t *testing.T, c *ResilientClient) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := c.WaitAvailable(ctx); err != nil {
+		t.Fatalf("driver did not become available: %v", err)
+	}
+}
+
+func TestResilientClient_ReconnectsOnConnectionLoss(t *testing.T) {
+	first := NewFakeClient("ebs.csi.aws.com", true, false)
+	unsupported := NewFakeClient("ebs.csi.aws.com", false, false)
+	second := NewFakeClient("ebs.csi.aws.com", true, false)
+	c, dialer := newTestResilientClient(t, first, nil, unsupported, second)
+
+	if _, err := c.Modify(context.TODO(), "vol-1", map[string]string{"iops": "3000"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dialer.handler(0)(context.TODO())
+	if c.Available() {
+		t.Fatal("expected driver to be unavailable after the connection loss")
+	}
+	if !first.IsClosed() {
+		t.Fatal("expected lost connection to be closed")
+	}
+	_, err := c.Modify(context.TODO(), "vol-1", map[string]string{"iops": "4000"}, nil, nil)
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable error while reconnecting, got %v", err)
+	}
+
+	close(dialer.release)
+	waitAvailable(t, c)
+	if !unsupported.IsClosed() {
+		t.Fatal("expected connection to a driver not supporting modification to be closed")
+	}
+	if _, err := c.Modify(context.TODO(), "vol-1", map[string]string{"iops": "4000"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if first.GetModifyCallCount() != 1 || second.GetModifyCallCount() != 1 {
+		t.Fatalf("expected the new connection to be used, got %d and %d modify calls", first.GetModifyCallCount(), second.GetModifyCallCount())
+	}
+
+	// A late notification for the old connection is ignored.
+	dialer.handler(0)(context.TODO())
+	if !c.Available() {
+		t.Fatal("expected stale connection loss to be ignored")
+	}
+}
+
+func TestResilientClient_KeepsConnectionOnDriverErrors(t *testing.T) {
+	first := NewFakeClient("ebs.csi.aws.com", true, false)
+	c, _ := newTestResilientClient(t, first)
+
+	// Drivers return Unavailable for their own transient errors, which must
+	// not interrupt the other calls on the connection.
+	for _, probeErr := range []error{
+		errors.New("driver is not ready"),
+		status.Error(codes.Unavailable, "backend is unavailable"),
+	} {
+		first.SetProbeError(probeErr)
+		if err := c.Probe(context.TODO()); err == nil {
+			t.Fatal("expected probe error")
+		}
+		if !c.Available() || first.IsClosed() {
+			t.Fatalf("expected %v to keep the connection", probeErr)
+		}
+	}
+}
+
+func TestResilientClient_WaitAvailableCancelled(t *testing.T) {
+	c, dialer := newTestResilientClient(t, NewFakeClient("ebs.csi.aws.com", true, false))
+	dialer.handler(0)(context.TODO())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.WaitAvailable(ctx); err == nil {
+		t.Fatal("expected WaitAvailable to return once the context is done")
+	}
+	// Let the reconnection loop observe the closed client and exit.
+	c.CloseConnection()
+	close(dialer.release)
+}
@@ -0,0 +1,430 @@
+// Package conformance checks that a CSI driver implements the Modify service
+// of modify.proto the way the sidecar expects.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config parametrizes the suite for the driver under test.
+type Config struct {
+	// VolumeID is an existing volume the suite modifies.
+	VolumeID string
+	// Parameters is a modification the driver accepts for the volume.
+	Parameters map[string]string
+	// UnknownParameter is a parameter the driver does not support.
+	UnknownParameter string
+	// MissingVolumeID is the ID of a volume that does not exist.
+	MissingVolumeID string
+	// Timeout bounds every call.
+	Timeout time.Duration
+	// OperationTimeout bounds how long asynchronous modifications are
+	// polled until they complete.
+	OperationTimeout time.Duration
+	// PollInterval is the interval at which GetModificationStatus is
+	// polled.
+	PollInterval time.Duration
+}
+
+// Defaults of the optional fields of Config.
+const (
+	DefaultUnknownParameter = "volumemodifier.k8s.aws/conformance-unknown-parameter"
+	DefaultMissingVolumeID  = "conformance-missing-volume"
+	DefaultTimeout          = 30 * time.Second
+	DefaultOperationTimeout = 5 * time.Minute
+	DefaultPollInterval     = time.Second
+)
+
+func (c Config) withDefaults() Config {
+	if c.UnknownParameter == "" {
+		c.UnknownParameter = DefaultUnknownParameter
+	}
+	if c.MissingVolumeID == "" {
+		c.MissingVolumeID = DefaultMissingVolumeID
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.OperationTimeout == 0 {
+		c.OperationTimeout = DefaultOperationTimeout
+	}
+	if c.PollInterval == 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	return c
+}
+
+// Outcome is the outcome of a check.
+type Outcome string
+
+const (
+	Passed  Outcome = "PASS"
+	Failed  Outcome = "FAIL"
+	Skipped Outcome = "SKIP"
+)
+
+// Result is the result of a check of the suite.
+type Result struct {
+	Name        string
+	Description string
+	Outcome     Outcome
+	// Message explains a failure or a skip.
+	Message string
+}
+
+// errSkipped makes a check skipped instead of failed.
+type errSkipped struct {
+	reason string
+}
+
+func (e *errSkipped) Error() string {
+	return e.reason
+}
+
+func skip(format string, args ...interface{}) error {
+	return &errSkipped{reason: fmt.Sprintf(format, args...)}
+}
+
+// check is a behavior the sidecar expects from the driver.
+type check struct {
+	name        string
+	description string
+	run         func(s *suite, ctx context.Context) error
+}
+
+// checks run in order. The modification checks run before the ones relying
+// on the volume being modified.
+var checks = []check{
+	{
+		name:        "identity",
+		description: "GetPluginInfo returns a name and Probe reports the driver ready",
+		run:         (*suite).checkIdentity,
+	},
+	{
+		name:        "capability",
+		description: "GetCSIDriverModificationCapability succeeds and its schema covers the parameters",
+		run:         (*suite).checkCapability,
+	},
+	{
+		name:        "modify",
+		description: "ModifyVolumeProperties applies the modification, synchronously or through GetModificationStatus",
+		run:         (*suite).checkModify,
+	},
+	{
+		name:        "idempotency",
+		description: "Repeating a completed modification succeeds",
+		run:         (*suite).checkIdempotency,
+	},
+	{
+		name:        "empty-context",
+		description: "ModifyVolumeProperties accepts a request without context",
+		run:         (*suite).checkEmptyContext,
+	},
+	{
+		name:        "unknown-parameter",
+		description: "ModifyVolumeProperties rejects an unknown parameter with InvalidArgument",
+		run:         (*suite).checkUnknownParameter,
+	},
+	{
+		name:        "missing-name",
+		description: "ModifyVolumeProperties rejects a request without volume name with InvalidArgument",
+		run:         (*suite).checkMissingName,
+	},
+	{
+		name:        "missing-parameters",
+		description: "ModifyVolumeProperties rejects a request without parameters with InvalidArgument",
+		run:         (*suite).checkMissingParameters,
+	},
+	{
+		name:        "missing-volume",
+		description: "ModifyVolumeProperties rejects a volume that does not exist with NotFound",
+		run:         (*suite).checkMissingVolume,
+	},
+	{
+		name:        "unknown-operation",
+		description: "GetModificationStatus rejects an unknown operation with NotFound",
+		run:         (*suite).checkUnknownOperation,
+	},
+	{
+		name:        "validate",
+		description: "ValidateVolumeModification accepts the modification and refuses an unknown parameter",
+		run:         (*suite).checkValidate,
+	},
+	{
+		name:        "volume-properties",
+		description: "GetVolumeProperties reports the applied modification",
+		run:         (*suite).checkVolumeProperties,
+	},
+}
+
+type suite struct {
+	config   Config
+	identity csi.IdentityClient
+	modify   modifyrpc.ModifyClient
+}
+
+// Run runs the suite against the driver served on conn and returns the
+// result of every check. The volume is modified.
+func Run(ctx context.Context, conn grpc.ClientConnInterface, config Config) ([]Result, error) {
+	if config.VolumeID == "" {
+		return nil, errors.New("the ID of the volume to modify is required")
+	}
+	if len(config.Parameters) == 0 {
+		return nil, errors.New("the parameters of the modification are required")
+	}
+	s := &suite{
+		config:   config.withDefaults(),
+		identity: csi.NewIdentityClient(conn),
+		modify:   modifyrpc.NewModifyClient(conn),
+	}
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		result := Result{Name: c.name, Description: c.description, Outcome: Passed}
+		var skipped *errSkipped
+		if err := c.run(s, ctx); errors.As(err, &skipped) {
+			result.Outcome = Skipped
+			result.Message = skipped.reason
+		} else if err != nil {
+			result.Outcome = Failed
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Succeeded reports whether no check failed.
+func Succeeded(results []Result) bool {
+	for _, result := range results {
+		if result.Outcome == Failed {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *suite) checkIdentity(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	info, err := s.identity.GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
+	if err != nil {
+		return fmt.Errorf("GetPluginInfo failed: %w", err)
+	}
+	if info.GetName() == "" {
+		return errors.New("GetPluginInfo returned an empty name")
+	}
+	probe, err := s.identity.Probe(ctx, &csi.ProbeRequest{})
+	if err != nil {
+		return fmt.Errorf("Probe failed: %w", err)
+	}
+	if probe.GetReady() != nil && !probe.GetReady().GetValue() {
+		return errors.New("Probe reported the driver not ready")
+	}
+	return nil
+}
+
+func (s *suite) checkCapability(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	resp, err := s.modify.GetCSIDriverModificationCapability(ctx, &modifyrpc.GetCSIDriverModificationCapabilityRequest{})
+	if err != nil {
+		return fmt.Errorf("GetCSIDriverModificationCapability failed: %w", err)
+	}
+	if len(resp.GetParameters()) == 0 {
+		return nil
+	}
+	advertised := make(map[string]bool)
+	for _, schema := range resp.GetParameters() {
+		if schema.GetName() == "" {
+			return errors.New("a parameter schema has no name")
+		}
+		advertised[schema.GetName()] = true
+	}
+	for key := range s.config.Parameters {
+		if !advertised[key] {
+			return fmt.Errorf("parameter %q is not in the advertised schema", key)
+		}
+	}
+	if advertised[s.config.UnknownParameter] {
+		return fmt.Errorf("unknown parameter %q is in the advertised schema", s.config.UnknownParameter)
+	}
+	return nil
+}
+
+// requestContext is sent like the context of the sidecar's requests.
+var requestContext = map[string]string{
+	"csi.storage.k8s.io/pv/name":          "conformance",
+	"volumemodifier.k8s.aws/requested-by": "annotation",
+}
+
+func (s *suite) checkModify(ctx context.Context) error {
+	return s.modifyAndWait(ctx, s.config.Parameters, requestContext)
+}
+
+// checkIdempotency repeats the modification of checkModify, as the sidecar
+// does when it fails to record a modification the driver applied.
+func (s *suite) checkIdempotency(ctx context.Context) error {
+	return s.modifyAndWait(ctx, s.config.Parameters, requestContext)
+}
+
+func (s *suite) checkEmptyContext(ctx context.Context) error {
+	return s.modifyAndWait(ctx, s.config.Parameters, nil)
+}
+
+// modifyAndWait modifies the volume and polls the modification until it
+// completes, if the driver applies it asynchronously.
+func (s *suite) modifyAndWait(ctx context.Context, params, reqContext map[string]string) error {
+	callCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	resp, err := s.modify.ModifyVolumeProperties(callCtx, &modifyrpc.ModifyVolumePropertiesRequest{
+		Name:       s.config.VolumeID,
+		Parameters: params,
+		Context:    reqContext,
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("ModifyVolumeProperties failed: %w", err)
+	}
+	operationID := resp.GetOperationId()
+	if operationID == "" {
+		return nil
+	}
+
+	ctx, cancel = context.WithTimeout(ctx, s.config.OperationTimeout)
+	defer cancel()
+	for {
+		callCtx, callCancel := context.WithTimeout(ctx, s.config.Timeout)
+		modificationStatus, err := s.modify.GetModificationStatus(callCtx, &modifyrpc.GetModificationStatusRequest{
+			Name:        s.config.VolumeID,
+			OperationId: operationID,
+		})
+		callCancel()
+		if err != nil {
+			return fmt.Errorf("GetModificationStatus of operation %s failed: %w", operationID, err)
+		}
+		switch modificationStatus.GetState() {
+		case modifyrpc.GetModificationStatusResponse_COMPLETED:
+			return nil
+		case modifyrpc.GetModificationStatusResponse_FAILED:
+			return fmt.Errorf("operation %s failed: %s", operationID, modificationStatus.GetMessage())
+		case modifyrpc.GetModificationStatusResponse_IN_PROGRESS:
+		default:
+			return fmt.Errorf("operation %s is in unexpected state %s", operationID, modificationStatus.GetState())
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("operation %s did not complete within %s", operationID, s.config.OperationTimeout)
+		case <-time.After(s.config.PollInterval):
+		}
+	}
+}
+
+// expectCode checks that a ModifyVolumeProperties request fails with code.
+func (s *suite) expectCode(ctx context.Context, req *modifyrpc.ModifyVolumePropertiesRequest, code codes.Code) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	_, err := s.modify.ModifyVolumeProperties(ctx, req)
+	if err == nil {
+		return fmt.Errorf("expected %s, the request succeeded", code)
+	}
+	if got := status.Code(err); got != code {
+		return fmt.Errorf("expected %s, got %s: %v", code, got, err)
+	}
+	return nil
+}
+
+func (s *suite) checkUnknownParameter(ctx context.Context) error {
+	params := maps.Clone(s.config.Parameters)
+	params[s.config.UnknownParameter] = "conformance"
+	return s.expectCode(ctx, &modifyrpc.ModifyVolumePropertiesRequest{Name: s.config.VolumeID, Parameters: params}, codes.InvalidArgument)
+}
+
+func (s *suite) checkMissingName(ctx context.Context) error {
+	return s.expectCode(ctx, &modifyrpc.ModifyVolumePropertiesRequest{Parameters: s.config.Parameters}, codes.InvalidArgument)
+}
+
+func (s *suite) checkMissingParameters(ctx context.Context) error {
+	return s.expectCode(ctx, &modifyrpc.ModifyVolumePropertiesRequest{Name: s.config.VolumeID}, codes.InvalidArgument)
+}
+
+func (s *suite) checkMissingVolume(ctx context.Context) error {
+	return s.expectCode(ctx, &modifyrpc.ModifyVolumePropertiesRequest{Name: s.config.MissingVolumeID, Parameters: s.config.Parameters}, codes.NotFound)
+}
+
+func (s *suite) checkUnknownOperation(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	_, err := s.modify.GetModificationStatus(ctx, &modifyrpc.GetModificationStatusRequest{
+		Name:        s.config.VolumeID,
+		OperationId: "conformance-unknown-operation",
+	})
+	switch status.Code(err) {
+	case codes.NotFound:
+		return nil
+	case codes.Unimplemented:
+		return skip("GetModificationStatus is not implemented")
+	case codes.OK:
+		return errors.New("expected NotFound, the request succeeded")
+	default:
+		return fmt.Errorf("expected NotFound, got %s: %v", status.Code(err), err)
+	}
+}
+
+func (s *suite) checkValidate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	resp, err := s.modify.ValidateVolumeModification(ctx, &modifyrpc.ValidateVolumeModificationRequest{
+		Name:       s.config.VolumeID,
+		Parameters: s.config.Parameters,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		return skip("ValidateVolumeModification is not implemented")
+	}
+	if err != nil {
+		return fmt.Errorf("ValidateVolumeModification failed: %w", err)
+	}
+	if !resp.GetSupported() {
+		return fmt.Errorf("the modification is reported unsupported: %s", resp.GetMessage())
+	}
+
+	params := maps.Clone(s.config.Parameters)
+	params[s.config.UnknownParameter] = "conformance"
+	resp, err = s.modify.ValidateVolumeModification(ctx, &modifyrpc.ValidateVolumeModificationRequest{
+		Name:       s.config.VolumeID,
+		Parameters: params,
+	})
+	if err != nil {
+		return fmt.Errorf("ValidateVolumeModification with an unknown parameter failed: %w", err)
+	}
+	if resp.GetSupported() {
+		return fmt.Errorf("the unknown parameter %q is reported supported", s.config.UnknownParameter)
+	}
+	return nil
+}
+
+func (s *suite) checkVolumeProperties(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	resp, err := s.modify.GetVolumeProperties(ctx, &modifyrpc.GetVolumePropertiesRequest{Name: s.config.VolumeID})
+	if status.Code(err) == codes.Unimplemented {
+		return skip("GetVolumeProperties is not implemented")
+	}
+	if err != nil {
+		return fmt.Errorf("GetVolumeProperties failed: %w", err)
+	}
+	for key, value := range s.config.Parameters {
+		if got, ok := resp.GetParameters()[key]; !ok || got != value {
+			return fmt.Errorf("parameter %q is %q, expected %q", key, got, value)
+		}
+	}
+	return nil
+}
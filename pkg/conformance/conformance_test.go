@@ -0,0 +1,170 @@
+package conformance
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/fakedriver"
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// connect serves d on a socket and returns a connection to it.
+func connect(t *testing.T, d *fakedriver.Driver) *grpc.ClientConn {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "conformance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "csi.sock")
+	if err := d.Start(socket); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.Stop)
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newConformingDriver(opts ...fakedriver.Option) *fakedriver.Driver {
+	opts = append([]fakedriver.Option{fakedriver.WithParameters(
+		&modifyrpc.ParameterSchema{Name: "iops", Type: modifyrpc.ParameterSchema_INTEGER},
+	)}, opts...)
+	d := fakedriver.New("fake.csi.k8s.io", opts...)
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+	return d
+}
+
+var testConfig = Config{
+	VolumeID:     "vol-1",
+	Parameters:   map[string]string{"iops": "5000"},
+	Timeout:      5 * time.Second,
+	PollInterval: 10 * time.Millisecond,
+}
+
+func outcomes(results []Result) map[string]Outcome {
+	outcomes := make(map[string]Outcome)
+	for _, result := range results {
+		outcomes[result.Name] = result.Outcome
+	}
+	return outcomes
+}
+
+func TestRun_ReferenceDriver(t *testing.T) {
+	tests := map[string]*fakedriver.Driver{
+		"synchronous":  newConformingDriver(),
+		"asynchronous": newConformingDriver(fakedriver.WithAsyncModification(50 * time.Millisecond)),
+	}
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := Run(context.TODO(), connect(t, d), testConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(checks) {
+				t.Fatalf("expected %d results, got %d", len(checks), len(results))
+			}
+			for _, result := range results {
+				if result.Outcome != Passed {
+					t.Errorf("%s: %s %s", result.Name, result.Outcome, result.Message)
+				}
+			}
+			if !Succeeded(results) {
+				t.Fatal("expected the reference driver to pass the suite")
+			}
+		})
+	}
+}
+
+func TestRun_NonConformingDriver(t *testing.T) {
+	// Without a schema, the driver accepts unknown parameters.
+	d := fakedriver.New("fake.csi.k8s.io")
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+
+	results, err := Run(context.TODO(), connect(t, d), testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := outcomes(results)
+	if got["unknown-parameter"] != Failed || got["validate"] != Failed {
+		t.Fatalf("expected unknown parameters checks to fail, got %v", got)
+	}
+	if got["modify"] != Passed || got["missing-volume"] != Passed {
+		t.Fatalf("expected other checks to pass, got %v", got)
+	}
+	if Succeeded(results) {
+		t.Fatal("expected the suite to fail")
+	}
+}
+
+// unimplementedDriver does not implement the optional calls.
+type unimplementedDriver struct {
+	*fakedriver.Driver
+}
+
+func (unimplementedDriver) ValidateVolumeModification(context.Context, *modifyrpc.ValidateVolumeModificationRequest) (*modifyrpc.ValidateVolumeModificationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ValidateVolumeModification not implemented")
+}
+
+func (unimplementedDriver) GetVolumeProperties(context.Context, *modifyrpc.GetVolumePropertiesRequest) (*modifyrpc.GetVolumePropertiesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetVolumeProperties not implemented")
+}
+
+func TestRun_UnimplementedOptionalCalls(t *testing.T) {
+	d := newConformingDriver()
+	dir, err := os.MkdirTemp("", "conformance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "csi.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	csi.RegisterIdentityServer(server, d)
+	modifyrpc.RegisterModifyServer(server, unimplementedDriver{d})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	results, err := Run(context.TODO(), conn, testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := outcomes(results)
+	if got["validate"] != Skipped || got["volume-properties"] != Skipped {
+		t.Fatalf("expected unimplemented optional calls to be skipped, got %v", got)
+	}
+	if !Succeeded(results) {
+		t.Fatalf("expected skipped checks not to fail the suite, got %v", got)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	if _, err := Run(context.TODO(), nil, Config{Parameters: map[string]string{"iops": "5000"}}); err == nil {
+		t.Fatal("expected an error without volume ID")
+	}
+	if _, err := Run(context.TODO(), nil, Config{VolumeID: "vol-1"}); err == nil {
+		t.Fatal("expected an error without parameters")
+	}
+}
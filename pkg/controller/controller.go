@@ -3,10 +3,12 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
 	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
 	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
 	v1 "k8s.io/api/core/v1"
@@ -25,6 +27,8 @@ import (
 
 type ModifyController interface {
 	Run(int, context.Context)
+	// HasSynced returns true once the informers of the controller have synced.
+	HasSynced() bool
 }
 
 func NewModifyController(
@@ -35,9 +39,11 @@ func NewModifyController(
 	informerFactory informers.SharedInformerFactory,
 	pvcRateLimiter workqueue.RateLimiter,
 	retryModificationFailures bool,
+	opts ...Option,
 ) ModifyController {
 	pvInformer := informerFactory.Core().V1().PersistentVolumes()
 	pvcInformer := informerFactory.Core().V1().PersistentVolumeClaims()
+	classInformer := informerFactory.Storage().V1().StorageClasses()
 	claimQueue := workqueue.NewNamedRateLimitingQueue(pvcRateLimiter, fmt.Sprintf("%s-modify-pvc", name))
 
 	eventBroadcaster := record.NewBroadcaster()
@@ -53,18 +59,37 @@ func NewModifyController(
 		claimQueue:             claimQueue,
 		pvSynced:               pvInformer.Informer().HasSynced,
 		pvcSynced:              pvcInformer.Informer().HasSynced,
+		classesSynced:          classInformer.Informer().HasSynced,
 		volumes:                pvInformer.Informer().GetStore(),
 		claims:                 pvcInformer.Informer().GetStore(),
+		classes:                classInformer.Informer().GetStore(),
 		eventRecorder:          eventRecorder,
 		modificationInProgress: make(map[string]struct{}),
+		operations:             make(map[string]*modificationOperation),
+		driftedClaims:          make(map[string]struct{}),
+		requestTimes:           make(map[string]time.Time),
+		quotaReservations:      make(map[string]map[string]string),
 		retryFailures:          retryModificationFailures,
+		pollInterval:           defaultModificationPollInterval,
+		shutdownTimeout:        defaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(ctrl)
 	}
 
-	pvcInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+	ctrl.handlers.addWithResyncPeriod(pvcInformer.Informer(), cache.ResourceEventHandlerFuncs{
 		AddFunc:    ctrl.addPVC,
 		UpdateFunc: ctrl.updatePVC,
 		DeleteFunc: ctrl.deletePVC,
 	}, resyncPeriod)
+	ctrl.handlers.add(classInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		UpdateFunc: ctrl.updateStorageClass,
+	})
+	if ctrl.quotaInformer != nil {
+		ctrl.handlers.add(ctrl.quotaInformer, cache.ResourceEventHandlerFuncs{
+			UpdateFunc: ctrl.updateQuota,
+		})
+	}
 	informerFactory.Start(wait.NeverStop)
 
 	return ctrl
@@ -79,30 +104,82 @@ type modifyController struct {
 	eventRecorder record.EventRecorder
 	pvSynced      cache.InformerSynced
 	pvcSynced     cache.InformerSynced
+	classesSynced cache.InformerSynced
+	handlers      eventHandlers
 
 	modificationInProgress   map[string]struct{}
 	modificationInProgressMu sync.Mutex
 
+	// operations holds the asynchronous modifications that the driver is
+	// still applying, keyed by PVC.
+	operations   map[string]*modificationOperation
+	operationsMu sync.Mutex
+	pollInterval time.Duration
+
+	dryRun bool
+
+	// contextLabels and contextAnnotations are the labels and annotations
+	// of the PVC passed to the driver in the context of a modification.
+	contextLabels      []string
+	contextAnnotations []string
+
+	cooldown time.Duration
+
+	driftCheckInterval time.Duration
+	driftReapply       bool
+	// driftedClaims holds the PVCs whose volume drifted from the desired
+	// state and must be modified again.
+	driftedClaims   map[string]struct{}
+	driftedClaimsMu sync.Mutex
+
+	// requestTimes holds when a changed modification request was last
+	// observed on each PVC, for the modification duration metric.
+	requestTimes   map[string]time.Time
+	requestTimesMu sync.Mutex
+
 	volumes cache.Store
 	claims  cache.Store
+	classes cache.Store
+
+	// quotas is only set when VolumeModificationQuotas are enforced.
+	quotas        listers.VolumeModificationQuotaLister
+	quotaInformer cache.SharedIndexInformer
+	// quotaReservations holds the parameters of the modifications that
+	// passed the quota check and are not recorded on their PV yet, keyed
+	// by PV name.
+	quotaReservations map[string]map[string]string
+	quotaMu           sync.Mutex
 
 	retryFailures bool
+
+	// shutdownTimeout bounds how long Run waits for in-flight
+	// modifications once its context is cancelled.
+	shutdownTimeout time.Duration
+
+	// driver, if set, pauses the workers while the driver is unavailable.
+	driver DriverAvailability
 }
 
 func (c *modifyController) Run(workers int, ctx context.Context) {
 	defer c.claimQueue.ShutDown()
+	defer c.handlers.removeAll()
 
 	klog.InfoS("Starting external modifier", "name", c.name)
 	defer klog.InfoS("Shutting down external modifier", "name", c.name)
 
 	stopCh := ctx.Done()
-	informersSyncd := []cache.InformerSynced{c.pvSynced, c.pvcSynced}
+	informersSyncd := []cache.InformerSynced{c.pvSynced, c.pvcSynced, c.classesSynced}
+	if c.quotaInformer != nil {
+		informersSyncd = append(informersSyncd, c.quotaInformer.HasSynced)
+	}
 
 	if !cache.WaitForCacheSync(stopCh, informersSyncd...) {
-		klog.Errorf("Cannot sync pv or pvc caches")
+		klog.Errorf("Cannot sync pv, pvc or storage class caches")
 		return
 	}
 
+	c.restoreOperations()
+
 	// On startup, queue all existing PVCs - this ensures that PVCs
 	// awaiting modification (or otherwise in an inconsistent state)
 	// are processed immediately instead of waiting on a resync
@@ -110,11 +187,54 @@ func (c *modifyController) Run(workers int, ctx context.Context) {
 		c.addPVC(claim)
 	}
 
+	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
-		go wait.Until(c.syncPVCs, 0, stopCh)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(func() {
+				if c.driver != nil && c.driver.WaitAvailable(ctx) != nil {
+					return
+				}
+				c.syncPVCs()
+			}, 0, stopCh)
+		}()
+	}
+
+	if c.driftCheckInterval > 0 {
+		go wait.Until(c.checkDrift, c.driftCheckInterval, stopCh)
 	}
 
 	<-stopCh
+	c.drain(&wg)
+}
+
+// drain stops handing out queued PVCs and waits, up to shutdownTimeout, for
+// the workers to finish the modifications they already started so that their
+// results are persisted on the PV and PVC before the controller exits.
+func (c *modifyController) drain(workers *sync.WaitGroup) {
+	c.claimQueue.ShutDown()
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	klog.InfoS("Waiting for in-flight modifications to finish", "name", c.name, "timeout", c.shutdownTimeout)
+	select {
+	case <-done:
+		klog.InfoS("All in-flight modifications finished", "name", c.name)
+	case <-time.After(c.shutdownTimeout):
+		klog.Warningf("Timed out waiting for in-flight modifications of PVCs %v to finish", c.inProgressKeys())
+	}
+}
+
+func (c *modifyController) HasSynced() bool {
+	if c.quotaInformer != nil && !c.quotaInformer.HasSynced() {
+		return false
+	}
+	return c.pvSynced() && c.pvcSynced() && c.classesSynced()
 }
 
 func (c *modifyController) addPVC(obj interface{}) {
@@ -140,6 +260,7 @@ func (c *modifyController) updatePVC(old, new interface{}) {
 	}
 
 	if c.needsProcessing(oldPvc, newPvc) {
+		c.markRequested(util.PVCKey(newPvc))
 		c.addPVC(new)
 	}
 }
@@ -152,6 +273,9 @@ func (c *modifyController) deletePVC(obj interface{}) {
 	}
 	c.claimQueue.Forget(objKey)
 	c.claimQueue.Done(objKey)
+	c.removeOperation(objKey)
+	c.clearDrifted(objKey)
+	c.forgetRequested(objKey)
 }
 
 func (c *modifyController) syncPVCs() {
@@ -161,9 +285,24 @@ func (c *modifyController) syncPVCs() {
 	}
 	defer c.claimQueue.Done(key)
 
+	// The queue still hands out the remaining items after ShutDown. They
+	// are left to the next leader, which requeues every PVC on startup.
+	if c.claimQueue.ShuttingDown() {
+		klog.V(4).InfoS("Controller is shutting down, skipping PVC", "key", key)
+		return
+	}
+
 	if err := c.syncPVC(key.(string)); err != nil {
 		klog.ErrorS(err, "error syncing PVC", "key", key)
-		if c.retryFailures {
+		if !c.retryFailures {
+			return
+		}
+		if delay := retryAfter(err); delay > 0 {
+			// Honor the delay the driver asked for, e.g. when throttled,
+			// instead of the backoff of earlier failures.
+			c.claimQueue.Forget(key)
+			c.claimQueue.AddAfter(key, delay)
+		} else {
 			c.claimQueue.AddRateLimited(key)
 		}
 	} else {
@@ -195,6 +334,7 @@ func (c *modifyController) syncPVC(key string) error {
 
 	if pvc.Spec.VolumeName == "" {
 		klog.InfoS("PV bound to PVC is not created yet", "pvc", util.PVCKey(pvc))
+		c.updateModificationStatus(pvc, c.paramsFromAnnotations(pvc), ModificationStatePending, "Waiting for PVC to be bound")
 		return nil
 	}
 
@@ -205,6 +345,7 @@ func (c *modifyController) syncPVC(key string) error {
 
 	if !exists {
 		klog.Warningf("PV %q bound to PVC %s not found", pvc.Spec.VolumeName, util.PVCKey(pvc))
+		c.updateModificationStatus(pvc, c.paramsFromAnnotations(pvc), ModificationStatePending, fmt.Sprintf("Waiting for PV %s to be created", pvc.Spec.VolumeName))
 		return nil
 	}
 
@@ -213,45 +354,83 @@ func (c *modifyController) syncPVC(key string) error {
 		return fmt.Errorf("expected volume but got %+v", volumeObj)
 	}
 
-	if !c.pvcNeedsModification(pv, pvc) {
+	if op := c.getOperation(key); op != nil {
+		return c.pollModification(pv, pvc, op)
+	}
+
+	if c.isRollbackRequested(pvc) {
+		if pvc, err = c.rollbackPVC(pv, pvc); err != nil || pvc == nil {
+			return err
+		}
+	}
+
+	needsModification, scheduled, err := c.pvcNeedsModification(pv, pvc)
+	if err != nil {
+		c.refuseSchedule(pv, pvc, err)
+		return nil
+	}
+	if !needsModification {
 		klog.InfoS("No need to modify PVC", "pvc", util.PVCKey(pvc))
 		return nil
 	}
+	if !scheduled.IsZero() {
+		c.deferForSchedule(pv, pvc, scheduled)
+		return nil
+	}
 
 	return c.modifyPVC(pv, pvc)
 }
 
-// Determines if the PVC needs modification.
-func (c *modifyController) pvcNeedsModification(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) bool {
+// Determines if the PVC needs modification. If it does but the modification
+// is scheduled for later, the scheduled time is returned as well. An error is
+// returned if the schedule of the modification is invalid.
+func (c *modifyController) pvcNeedsModification(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) (bool, time.Time, error) {
 	// Check if there's already a modification going on.
 	if c.ifPVCModificationInProgress(pvc) {
 		klog.InfoS("modification for pvc is already undergoing", "pvc", util.PVCKey(pvc))
-		return false
+		return false, time.Time{}, nil
 	}
 
 	// Only Bound PVC can be modified
 	if pvc.Status.Phase != v1.ClaimBound {
 		klog.InfoS("pvc is not bound", "pvc", util.PVCKey(pvc))
-		return false
+		return false, time.Time{}, nil
 	}
 
 	if pvc.Spec.VolumeName == "" {
 		klog.InfoS("volume name is empty", "pvc", util.PVCKey(pvc))
-		return false
+		return false, time.Time{}, nil
 	}
 
-	if !c.annotationsUpdated(pvc.Annotations, pv.Annotations) {
+	if c.isDrifted(util.PVCKey(pvc)) {
+		klog.InfoS("volume drifted from the desired state", "pvc", util.PVCKey(pvc))
+	} else if !c.annotationsUpdated(pvc.Annotations, pv.Annotations) {
 		klog.InfoS("annotations not updated", "pvc", util.PVCKey(pvc))
-		return false
+		return false, time.Time{}, nil
 	}
 
-	return true
+	// Validation does not modify the volume and is not scheduled.
+	if c.isDryRun(pvc) {
+		return true, time.Time{}, nil
+	}
+	// Requeues, e.g. on drift, quota or policy changes, do not issue again a
+	// modification the driver rejected as invalid.
+	if c.failedTerminally(pvc, c.paramsFromAnnotations(pvc)) {
+		klog.InfoS("modification failed with a terminal error, not retrying until the annotations change", "pvc", util.PVCKey(pvc))
+		return false, time.Time{}, nil
+	}
+	scheduled, err := c.scheduledModificationTime(pvc, time.Now())
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	return true, scheduled, nil
 }
 
 func (c *modifyController) addPVCToInProgressList(pvc *v1.PersistentVolumeClaim) {
 	c.modificationInProgressMu.Lock()
-	defer c.modificationInProgressMu.Unlock()
 	c.modificationInProgress[util.PVCKey(pvc)] = struct{}{}
+	c.modificationInProgressMu.Unlock()
+	c.updateInProgressGauge()
 }
 
 func (c *modifyController) ifPVCModificationInProgress(pvc *v1.PersistentVolumeClaim) bool {
@@ -261,60 +440,202 @@ func (c *modifyController) ifPVCModificationInProgress(pvc *v1.PersistentVolumeC
 	return ok
 }
 
-func (c *modifyController) removePVCFromInProgressList(pvc *v1.PersistentVolumeClaim) {
+func (c *modifyController) inProgressKeys() []string {
 	c.modificationInProgressMu.Lock()
 	defer c.modificationInProgressMu.Unlock()
+	keys := make([]string, 0, len(c.modificationInProgress))
+	for key := range c.modificationInProgress {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *modifyController) removePVCFromInProgressList(pvc *v1.PersistentVolumeClaim) {
+	c.modificationInProgressMu.Lock()
 	delete(c.modificationInProgress, util.PVCKey(pvc))
+	c.modificationInProgressMu.Unlock()
+	c.updateInProgressGauge()
+}
+
+// updateInProgressGauge counts the PVCs being processed by a worker or whose
+// modification the driver is still applying.
+func (c *modifyController) updateInProgressGauge() {
+	c.modificationInProgressMu.Lock()
+	defer c.modificationInProgressMu.Unlock()
+	c.operationsMu.Lock()
+	defer c.operationsMu.Unlock()
+	count := len(c.modificationInProgress)
+	for key := range c.operations {
+		if _, ok := c.modificationInProgress[key]; !ok {
+			count++
+		}
+	}
+	modificationsInProgress.WithLabelValues(c.name).Set(float64(count))
 }
 
 func (c *modifyController) modifyPVC(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) error {
 	c.addPVCToInProgressList(pvc)
 	defer c.removePVCFromInProgressList(pvc)
 
+	params := c.paramsFromAnnotations(pvc)
+
 	if pvc.Spec.VolumeAttributesClassName != nil && *pvc.Spec.VolumeAttributesClassName != "" {
+		vacConflictRefusalsTotal.WithLabelValues(c.name, pvc.Namespace).Inc()
 		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Refusing to modify %s (via annotation) because PVC %s has a VAC associated", pv.Name, pvc.Name)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, "PVC has a VolumeAttributesClass associated")
 		return fmt.Errorf("Refusing to modify because PVC has a VAC associated")
 	} else if pv.Spec.VolumeAttributesClassName != nil && *pv.Spec.VolumeAttributesClassName != "" {
+		vacConflictRefusalsTotal.WithLabelValues(c.name, pvc.Namespace).Inc()
 		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Refusing to modify %s (via annotation) because it has a VAC associated", pv.Name)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, "PV has a VolumeAttributesClass associated")
 		return fmt.Errorf("Refusing to modify because PV has a VAC associated")
 	}
 
-	params := make(map[string]string)
-	for key, value := range pvc.Annotations {
-		if c.isValidAnnotation(key) {
-			params[c.attributeFromValidAnnotation(key)] = value
-		}
+	capability, err := c.modifier.GetModificationCapability()
+	if err != nil {
+		return fmt.Errorf("cannot get modification capability of driver %q: %w", c.name, err)
+	}
+	if err := capability.Validate(params); err != nil {
+		// Retrying cannot help until the annotations are changed, which
+		// requeues the PVC.
+		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Refusing to modify %s because of invalid annotations: %v", pv.Name, err)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, err.Error())
+		return nil
+	}
+	if err := c.checkModificationPolicy(pv, pvc, params); err != nil {
+		// The PVC is requeued when the policy changes.
+		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Refusing to modify %s: %v", pv.Name, err)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, err.Error())
+		return nil
+	}
+
+	requestedBy := RequestedByAnnotation
+	if kind, name := requester(c.name, pvc); kind != "" {
+		requestedBy = kind + "/" + name
+	}
+	reqContext := requestContext(pv, pvc, requestedBy, c.contextLabels, c.contextAnnotations)
+
+	if c.isDryRun(pvc) {
+		return c.validatePVC(pv, pvc, params, reqContext)
+	}
+
+	if deferred := c.deferForCooldown(pv, pvc, params, capability.Cooldown); deferred {
+		return nil
+	}
+
+	release, err := c.checkQuota(pv, pvc, params)
+	if err != nil {
+		// The PVC is requeued when the quota changes.
+		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Refusing to modify %s: %v", pv.Name, err)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, err.Error())
+		return nil
 	}
+	defer release()
 
-	reqContext := make(map[string]string)
+	secrets, err := getModifierSecrets(c.kubeClient, c.classes, pv, pvc)
+	if err != nil {
+		// The Secret may not exist yet, retry with backoff.
+		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Cannot modify %s: %v", pv.Name, err)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, err.Error())
+		return err
+	}
 
 	c.eventRecorder.Event(pvc, v1.EventTypeNormal, VolumeModificationStarted, fmt.Sprintf("External modifier is modifying volume %s", pv.Name))
+	pvc = c.updateModificationStatus(pvc, params, ModificationStateInProgress, "")
+	c.recordModifications(modificationsStartedTotal, pvc, params)
 
-	err := c.modifier.Modify(pv, params, reqContext)
+	operationID, err := c.modifier.Modify(pv, params, reqContext, secrets)
 	if err != nil {
-		c.eventRecorder.Event(pvc, v1.EventTypeWarning, VolumeModificationFailed, err.Error())
+		c.recordModifications(modificationsFailedTotal, pvc, params)
+		c.eventRecorder.Event(pvc, v1.EventTypeWarning, VolumeModificationFailed, c.modificationFailureMessage(pv.Name, err))
+		c.recordFailedModification(pv, pvc, params, err.Error())
+		c.updateFailedModificationStatus(pvc, params, err)
+		if isTerminalError(err) {
+			// Retrying cannot help until the annotations are changed, which
+			// requeues the PVC.
+			return nil
+		}
 		return fmt.Errorf("modification of volume %q failed by modifier %q: %w", pvc.Name, c.name, err)
-	} else {
-		c.eventRecorder.Eventf(pvc, v1.EventTypeNormal, VolumeModificationSuccessful, "External modifier has successfully modified volume %s", pv.Name)
 	}
 
-	return c.markPVCModificationComplete(pv, params)
+	if operationID != "" {
+		klog.InfoS("Volume modification is in progress", "pvc", util.PVCKey(pvc), "operationID", operationID)
+		c.addOperation(pvc, &modificationOperation{id: operationID, params: params})
+		c.claimQueue.AddAfter(util.PVCKey(pvc), c.pollInterval)
+		return nil
+	}
+
+	return c.completeModification(pv, pvc, params)
+}
+
+// completeModification records a modification the driver has finished applying.
+func (c *modifyController) completeModification(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string) error {
+	c.eventRecorder.Eventf(pvc, v1.EventTypeNormal, VolumeModificationSuccessful, "External modifier has successfully modified volume %s", pv.Name)
+
+	if err := c.markPVCModificationComplete(pv, pvc, params); err != nil {
+		c.recordModifications(modificationsFailedTotal, pvc, params)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, err.Error())
+		return err
+	}
+	// The volume is only back in the desired state once the driver applied
+	// the modification.
+	c.clearDrifted(util.PVCKey(pvc))
+	c.recordModifications(modificationsSucceededTotal, pvc, params)
+	c.observeModificationDuration(util.PVCKey(pvc))
+	c.updateModificationStatus(pvc, params, ModificationStateSucceeded, "")
+	return nil
+}
+
+// paramsFromAnnotations returns the modification parameters requested
+// through "<driver-name>/" annotations on the PVC.
+func (c *modifyController) paramsFromAnnotations(pvc *v1.PersistentVolumeClaim) map[string]string {
+	params := make(map[string]string)
+	for key, value := range pvc.Annotations {
+		if c.isValidAnnotation(key) {
+			params[c.attributeFromValidAnnotation(key)] = value
+		}
+	}
+	return params
 }
 
 func (c *modifyController) isValidAnnotation(ann string) bool {
-	return strings.HasPrefix(ann, fmt.Sprintf(AnnotationPrefixPattern, c.name)) &&
-		!strings.HasSuffix(ann, "-status")
+	return IsParameterAnnotation(c.name, ann)
+}
+
+// IsParameterAnnotation returns true if the PVC annotation requests the
+// modification of a parameter of the driver's volumes, i.e. it has the
+// "<driver-name>/" prefix and is neither a status nor a reserved annotation.
+func IsParameterAnnotation(driverName, ann string) bool {
+	return strings.HasPrefix(ann, fmt.Sprintf(AnnotationPrefixPattern, driverName)) &&
+		!strings.HasSuffix(ann, "-status") &&
+		!isReservedAnnotation(driverName, ann)
+}
+
+func isReservedAnnotation(driverName, ann string) bool {
+	for _, pattern := range reservedAnnotationPatterns {
+		if ann == fmt.Sprintf(pattern, driverName) {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *modifyController) attributeFromValidAnnotation(ann string) string {
 	return strings.TrimPrefix(ann, fmt.Sprintf(AnnotationPrefixPattern, c.name))
 }
 
-func (c *modifyController) markPVCModificationComplete(oldPV *v1.PersistentVolume, params map[string]string) error {
+func (c *modifyController) markPVCModificationComplete(oldPV *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string) error {
 	newPV := oldPV.DeepCopy()
+	if newPV.Annotations == nil {
+		newPV.Annotations = make(map[string]string)
+	}
+	recordPreviousParameters(c.name, oldPV, newPV, params)
+	appendModificationHistory(c.name, oldPV, newPV, pvc, params, util.ModificationResultSucceeded, "")
 	for key, value := range params {
 		newPV.Annotations[fmt.Sprintf("%s/%s", c.name, key)] = value
 	}
+	newPV.Annotations[fmt.Sprintf(AnnotationLastModificationTimePattern, c.name)] = time.Now().UTC().Format(time.RFC3339)
 
 	_, err := c.patchPV(oldPV, newPV, true)
 	return err
@@ -367,6 +688,11 @@ func (c *modifyController) needsProcessing(old *v1.PersistentVolumeClaim, new *v
 		return false
 	}
 
+	rollbackKey := fmt.Sprintf(AnnotationRollbackPattern, c.name)
+	if old.Annotations[rollbackKey] != new.Annotations[rollbackKey] && c.isRollbackRequested(new) {
+		return true
+	}
+
 	annotations := make(map[string]struct{})
 	for key := range new.Annotations {
 		if c.isValidAnnotation(key) {
@@ -383,6 +709,15 @@ func (c *modifyController) needsProcessing(old *v1.PersistentVolumeClaim, new *v
 		}
 	}
 
+	if len(annotations) > 0 {
+		for _, pattern := range []string{AnnotationDryRunPattern, AnnotationModifyAfterPattern, AnnotationMaintenanceWindowPattern} {
+			key := fmt.Sprintf(pattern, c.name)
+			if old.Annotations[key] != new.Annotations[key] {
+				return true
+			}
+		}
+	}
+
 	hasBeenBound := old.Status.Phase != new.Status.Phase && new.Status.Phase == v1.ClaimBound
 	// If the annotation was set at creation we might have skipped the PVC because it was not bound yet
 	return len(annotations) > 0 && hasBeenBound
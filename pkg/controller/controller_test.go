@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -32,6 +33,11 @@ func newTestController(name string) *modifyController {
 		annPrefix:              fmt.Sprintf(AnnotationPrefixPattern, name),
 		claimQueue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
 		modificationInProgress: make(map[string]struct{}),
+		operations:             make(map[string]*modificationOperation),
+		driftedClaims:          make(map[string]struct{}),
+		requestTimes:           make(map[string]time.Time),
+		pollInterval:           defaultModificationPollInterval,
+		shutdownTimeout:        defaultShutdownTimeout,
 	}
 }
 
@@ -97,7 +103,22 @@ func setupController(
 ) (*modifyController, *csi.FakeClient) {
 	t.Helper()
 	client := csi.NewFakeClient(driverName, true, clientReturnsError)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, objects...)
+	waitForCacheSync(t, ctrl, 3*time.Second)
+	return ctrl, client
+}
 
+// setupControllerWithClient is like setupController for a preconfigured fake
+// CSI client and controller options.
+func setupControllerWithClient(t *testing.T, driverName string, client *csi.FakeClient, opts []Option, objects ...runtime.Object) *modifyController {
+	t.Helper()
+	return startController(t, driverName, client, workqueue.DefaultControllerRateLimiter(), false, opts, objects...)
+}
+
+// startController runs a controller against a fake clientset holding objects
+// until the test ends.
+func startController(t *testing.T, driverName string, client *csi.FakeClient, rateLimiter workqueue.RateLimiter, retryFailures bool, opts []Option, objects ...runtime.Object) *modifyController {
+	t.Helper()
 	k8sClient := fake.NewClientset(objects...)
 	factory := informers.NewSharedInformerFactory(k8sClient, 0)
 
@@ -106,15 +127,8 @@ func setupController(
 		t.Fatal(err)
 	}
 
-	mc := NewModifyController(
-		driverName,
-		mod,
-		k8sClient,
-		0,
-		factory,
-		workqueue.DefaultControllerRateLimiter(),
-		false,
-	)
+	mc := NewModifyController(driverName, mod, k8sClient, 0, factory,
+		rateLimiter, retryFailures, opts...)
 
 	stopCh := make(chan struct{})
 	factory.Start(stopCh)
@@ -126,10 +140,7 @@ func setupController(
 		cancel()
 		close(stopCh)
 	})
-
-	ctrl := mc.(*modifyController)
-	waitForCacheSync(t, ctrl, 3*time.Second)
-	return ctrl, client
+	return mc.(*modifyController)
 }
 
 func newFakePVC() *v1.PersistentVolumeClaim {
@@ -264,9 +275,11 @@ func newTestPV(name, pvcName, pvcNamespace string, pvcUID types.UID, driverName
 	return pv
 }
 
+// verifyNoAnnotationsOnPV checks that no parameter was recorded on the PV.
+// Failed modifications are only recorded in the modification history.
 func verifyNoAnnotationsOnPV(ann map[string]string, driverName string) error {
 	for k, v := range ann {
-		if strings.HasPrefix(k, driverName) {
+		if strings.HasPrefix(k, driverName) && k != fmt.Sprintf(AnnotationModificationHistoryPattern, driverName) {
 			return fmt.Errorf("found annotation on PV: %s (value: %s)", k, v)
 		}
 	}
@@ -601,6 +614,37 @@ func TestControllerRun_NilVACNotBlocked(t *testing.T) {
 	}
 }
 
+func TestModifyPVC_InvalidAnnotations(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	minIOPS := int64(3000)
+
+	pvc := newTestPVC("invalid-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iopss": "5000",
+		"ebs.csi.aws.com/iops":  "100",
+	})
+	pv := newTestPV("testPV", "invalid-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModificationCapability(&csi.ModificationCapability{
+		Parameters: map[string]csi.ParameterSchema{
+			"iops": {Name: "iops", Type: csi.ParameterTypeInteger, Min: &minIOPS},
+		},
+	})
+
+	ctrl := startController(t, driverName, client, workqueue.DefaultControllerRateLimiter(), true, nil, pvc, pv)
+
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "invalid-pvc", "iopss", ModificationStateFailed, 3*time.Second)
+	if !strings.Contains(status.Reason, `parameter "iopss" is not supported by the driver`) ||
+		!strings.Contains(status.Reason, `value 100 is below the minimum of 3000`) {
+		t.Fatalf("unexpected failure reason %q", status.Reason)
+	}
+
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected invalid annotations not to reach the driver, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
 func TestControllerRun_DeletePVC(t *testing.T) {
 	driverName := "ebs.csi.aws.com"
 	pvc := newTestPVC("del-test-pvc", "default", map[string]string{
@@ -722,10 +766,8 @@ func TestControllerRun_RetryOnFailure(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	for k := range updatedPV.Annotations {
-		if strings.HasPrefix(k, driverName) {
-			t.Fatalf("PV should not have driver annotations after failed modifications, found %s", k)
-		}
+	if err := verifyNoAnnotationsOnPV(updatedPV.Annotations, driverName); err != nil {
+		t.Fatalf("PV should not have driver annotations after failed modifications: %v", err)
 	}
 }
 
@@ -778,6 +820,10 @@ func TestIsValidAnnotation(t *testing.T) {
 		{"valid annotation", "ebs.csi.aws.com/volumeType", true},
 		{"valid iops annotation", "ebs.csi.aws.com/iops", true},
 		{"status annotation is invalid", "ebs.csi.aws.com/volumeType-status", false},
+		{"dry-run annotation is invalid", "ebs.csi.aws.com/dry-run", false},
+		{"last modification time annotation is invalid", "ebs.csi.aws.com/last-modification-time", false},
+		{"modify-after annotation is invalid", "ebs.csi.aws.com/modify-after", false},
+		{"maintenance window annotation is invalid", "ebs.csi.aws.com/maintenance-window", false},
 		{"different driver prefix", "other.driver.io/volumeType", false},
 		{"no prefix", "volumeType", false},
 		{"empty string", "", false},
@@ -879,6 +925,36 @@ func TestNeedsProcessing(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "maintenance window changed",
+			old: &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "1", Annotations: map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/maintenance-window": "0 2 * * SAT 4h"},
+			}},
+			new: &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "2", Annotations: map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/maintenance-window": "0 2 * * SUN 4h"},
+			}},
+			expected: true,
+		},
+		{
+			name: "rollback requested",
+			old: &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "1", Annotations: map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			}},
+			new: &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "2", Annotations: map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/rollback": "true"},
+			}},
+			expected: true,
+		},
+		{
+			name: "rollback request cleared",
+			old: &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "1", Annotations: map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/rollback": "true"},
+			}},
+			new: &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				ResourceVersion: "2", Annotations: map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			}},
+			expected: false,
+		},
 		{
 			name: "PVC becomes bound without driver annotations",
 			old: &v1.PersistentVolumeClaim{
@@ -963,6 +1039,45 @@ func TestPvcNeedsModification(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "terminal failure of the same parameters returns false",
+			pv:   &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"ebs.csi.aws.com/iops": "3000"}}},
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pvc", Namespace: "default", Annotations: map[string]string{
+					"ebs.csi.aws.com/iops":        "5000",
+					"ebs.csi.aws.com/iops-status": `{"state":"failed","value":"5000","failure":"terminal"}`,
+				}},
+				Spec:   v1.PersistentVolumeClaimSpec{VolumeName: "pv1"},
+				Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+			},
+			expected: false,
+		},
+		{
+			name: "terminal failure of other parameters returns true",
+			pv:   &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"ebs.csi.aws.com/iops": "3000"}}},
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pvc", Namespace: "default", Annotations: map[string]string{
+					"ebs.csi.aws.com/iops":        "6000",
+					"ebs.csi.aws.com/iops-status": `{"state":"failed","value":"5000","failure":"terminal"}`,
+				}},
+				Spec:   v1.PersistentVolumeClaimSpec{VolumeName: "pv1"},
+				Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+			},
+			expected: true,
+		},
+		{
+			name: "retryable failure of the same parameters returns true",
+			pv:   &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"ebs.csi.aws.com/iops": "3000"}}},
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pvc", Namespace: "default", Annotations: map[string]string{
+					"ebs.csi.aws.com/iops":        "5000",
+					"ebs.csi.aws.com/iops-status": `{"state":"failed","value":"5000","failure":"retryable"}`,
+				}},
+				Spec:   v1.PersistentVolumeClaimSpec{VolumeName: "pv1"},
+				Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+			},
+			expected: true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -970,7 +1085,7 @@ func TestPvcNeedsModification(t *testing.T) {
 			if tc.setup != nil {
 				tc.setup(ctrl)
 			}
-			if got := ctrl.pvcNeedsModification(tc.pv, tc.pvc); got != tc.expected {
+			if got, _, _ := ctrl.pvcNeedsModification(tc.pv, tc.pvc); got != tc.expected {
 				t.Errorf("pvcNeedsModification() = %v, want %v", got, tc.expected)
 			}
 		})
@@ -1101,3 +1216,141 @@ func TestSyncPVC_WrongTypeInClaimsStore(t *testing.T) {
 		t.Fatalf("unexpected error message: %v", err)
 	}
 }
+
+func runUntilCancelled(t *testing.T, driverName string, client *csi.FakeClient, opts []Option, objects ...runtime.Object) (kubernetes.Interface, context.CancelFunc, <-chan struct{}) {
+	t.Helper()
+	k8sClient := fake.NewClientset(objects...)
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+
+	mod, err := modifier.NewFromClient(driverName, client, k8sClient, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := NewModifyController(driverName, mod, k8sClient, 0, factory,
+		workqueue.DefaultControllerRateLimiter(), false, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	factory.Start(ctx.Done())
+
+	done := make(chan struct{})
+	go func() {
+		mc.Run(1, ctx)
+		close(done)
+	}()
+	t.Cleanup(cancel)
+	return k8sClient, cancel, done
+}
+
+func TestControllerRun_DrainsInFlightModification(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("drain-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "drain-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyDelay(500 * time.Millisecond)
+	k8sClient, cancel, done := runUntilCancelled(t, driverName, client, nil, pvc, pv)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the in-flight modification finished")
+	}
+
+	updatedPV, err := k8sClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := updatedPV.Annotations["ebs.csi.aws.com/iops"]; got != "5000" {
+		t.Fatalf("expected in-flight modification to be persisted on the PV, got iops annotation %q", got)
+	}
+}
+
+func TestControllerRun_ShutdownTimeout(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("slow-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "slow-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyDelay(3 * time.Second)
+	_, cancel, done := runUntilCancelled(t, driverName, client, []Option{WithShutdownTimeout(100 * time.Millisecond)}, pvc, pv)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after the shutdown timeout")
+	}
+}
+
+func TestSyncPVCs_SkipsQueuedPVCsOnShutdown(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("queued-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "queued-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	mc := newTestController(driverName)
+	mod, err := modifier.NewFromClient(driverName, client, fake.NewClientset(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc.modifier = mod
+	mc.claims = cache.NewStore(cache.MetaNamespaceKeyFunc)
+	mc.volumes = cache.NewStore(cache.MetaNamespaceKeyFunc)
+	mc.claims.Add(pvc)
+	mc.volumes.Add(pv)
+
+	mc.claimQueue.Add("default/queued-pvc")
+	mc.claimQueue.ShutDown()
+	mc.syncPVCs()
+
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call after shutdown, got %d", client.GetModifyCallCount())
+	}
+}
+
+// testDriverAvailability is available once available is closed.
+type testDriverAvailability struct {
+	available chan struct{}
+}
+
+func (d *testDriverAvailability) WaitAvailable(ctx context.Context) error {
+	select {
+	case <-d.available:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestControllerRun_PausesWhileDriverUnavailable(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("paused-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "paused-pvc", "default", "test-uid", driverName)
+
+	driver := &testDriverAvailability{available: make(chan struct{})}
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, []Option{WithDriverAvailability(driver)}, pvc, pv)
+
+	time.Sleep(200 * time.Millisecond)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modification while the driver is unavailable, got %d modify calls", client.GetModifyCallCount())
+	}
+	if ctrl.claimQueue.Len() != 1 {
+		t.Fatalf("expected the PVC to stay queued, got %d queued PVCs", ctrl.claimQueue.Len())
+	}
+
+	close(driver.available)
+	waitForModifyCount(t, client, 1, 3*time.Second)
+}
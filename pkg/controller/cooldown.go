@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// deferForCooldown requeues the PVC for when the cooldown window since the
+// last successful modification of its volume closes. It returns true if the
+// modification was deferred.
+func (c *modifyController) deferForCooldown(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string, driverCooldown time.Duration) bool {
+	cooldown := max(c.cooldown, driverCooldown)
+	if cooldown <= 0 {
+		return false
+	}
+
+	value, ok := pv.Annotations[fmt.Sprintf(AnnotationLastModificationTimePattern, c.name)]
+	if !ok {
+		return false
+	}
+	lastModified, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.ErrorS(err, "Ignoring invalid last modification time", "pv", pv.Name, "value", value)
+		return false
+	}
+
+	next := lastModified.Add(cooldown)
+	wait := time.Until(next)
+	if wait <= 0 {
+		return false
+	}
+
+	klog.InfoS("Deferring modification until cooldown expires", "pvc", util.PVCKey(pvc), "next", next)
+	message := fmt.Sprintf("Volume %s was last modified at %s; modification will be attempted after %s", pv.Name, lastModified.Format(time.RFC3339), next.Format(time.RFC3339))
+	c.eventRecorder.Event(pvc, v1.EventTypeNormal, VolumeModificationDeferred, message)
+	c.updateModificationStatus(pvc, params, ModificationStatePending, fmt.Sprintf("Waiting for cooldown until %s", next.Format(time.RFC3339)))
+	c.claimQueue.AddAfter(util.PVCKey(pvc), wait)
+	return true
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCooldown(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	tests := []struct {
+		name           string
+		lastModified   time.Duration
+		cooldown       time.Duration
+		driverCooldown time.Duration
+		expectDeferred bool
+	}{
+		{
+			name:           "no cooldown configured",
+			lastModified:   time.Minute,
+			expectDeferred: false,
+		},
+		{
+			name:           "within cooldown",
+			lastModified:   time.Minute,
+			cooldown:       time.Hour,
+			expectDeferred: true,
+		},
+		{
+			name:           "cooldown expired",
+			lastModified:   2 * time.Hour,
+			cooldown:       time.Hour,
+			expectDeferred: false,
+		},
+		{
+			name:           "within driver-advertised cooldown",
+			lastModified:   time.Hour,
+			cooldown:       time.Minute,
+			driverCooldown: 6 * time.Hour,
+			expectDeferred: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc := newTestPVC("cooldown-pvc", "default", map[string]string{
+				"ebs.csi.aws.com/iops": "5000",
+			})
+			pv := newTestPV("testPV", "cooldown-pvc", "default", "test-uid", driverName)
+			pv.Annotations["ebs.csi.aws.com/last-modification-time"] = time.Now().Add(-tc.lastModified).UTC().Format(time.RFC3339)
+
+			client := csi.NewFakeClient(driverName, true, false)
+			client.SetModificationCapability(&csi.ModificationCapability{Cooldown: tc.driverCooldown})
+
+			ctrl := setupControllerWithClient(t, driverName, client, []Option{WithModificationCooldown(tc.cooldown)}, pvc, pv)
+
+			if !tc.expectDeferred {
+				waitForModifyCount(t, client, 1, 3*time.Second)
+				return
+			}
+
+			event := waitForEvent(t, ctrl.kubeClient, VolumeModificationDeferred, 3*time.Second)
+			if !strings.Contains(event.Message, "modification will be attempted after") {
+				t.Fatalf("unexpected deferral event message %q", event.Message)
+			}
+			status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "cooldown-pvc", "iops", ModificationStatePending, 3*time.Second)
+			if !strings.HasPrefix(status.Reason, "Waiting for cooldown until") {
+				t.Fatalf("unexpected pending reason %q", status.Reason)
+			}
+			if client.GetModifyCallCount() != 0 {
+				t.Fatalf("expected modification to be deferred, got %d modify calls", client.GetModifyCallCount())
+			}
+		})
+	}
+}
+
+func TestCooldown_AttemptedWhenWindowOpens(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("cooldown-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "cooldown-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/last-modification-time"] = time.Now().UTC().Format(time.RFC3339)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, []Option{WithModificationCooldown(2 * time.Second)}, pvc, pv)
+
+	waitForEvent(t, ctrl.kubeClient, VolumeModificationDeferred, 3*time.Second)
+	waitForModifyCount(t, client, 1, 4*time.Second)
+}
+
+func TestMarkPVCModificationComplete_RecordsTime(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("time-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "time-pvc", "default", "test-uid", driverName)
+
+	ctrl, _ := setupController(t, driverName, false, pvc, pv)
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "time-pvc", "iops", ModificationStateSucceeded, 3*time.Second)
+
+	updatedPV, err := ctrl.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastModified, err := time.Parse(time.RFC3339, updatedPV.Annotations["ebs.csi.aws.com/last-modification-time"])
+	if err != nil {
+		t.Fatalf("expected last modification time on PV: %v", err)
+	}
+	if time.Since(lastModified) > time.Minute {
+		t.Fatalf("unexpected last modification time %v", lastModified)
+	}
+}
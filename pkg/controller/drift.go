@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// checkDrift compares the properties reported by the driver for every volume
+// modified by this controller with the values last applied to it. Volumes
+// being modified are skipped, as their properties are expected to change.
+func (c *modifyController) checkDrift() {
+	for _, obj := range c.volumes.List() {
+		pv, ok := obj.(*v1.PersistentVolume)
+		if !ok {
+			continue
+		}
+		if pvc := c.boundClaim(pv); pvc != nil && c.isModificationOutstanding(pvc) {
+			klog.V(4).InfoS("Skipping drift check of volume being modified", "pv", pv.Name, "pvc", util.PVCKey(pvc))
+			continue
+		}
+
+		drifted, err := c.volumeDrift(pv)
+		if err != nil {
+			klog.ErrorS(err, "Failed to check volume drift", "pv", pv.Name)
+			continue
+		}
+		if len(drifted) == 0 {
+			continue
+		}
+		c.handleDrift(pv, drifted)
+	}
+}
+
+// volumeDrift returns the parameters whose driver-reported value differs from
+// the value recorded on the PV, mapped to the reported value. Parameters the
+// driver does not report are ignored.
+func (c *modifyController) volumeDrift(pv *v1.PersistentVolume) (map[string]string, error) {
+	applied := make(map[string]string)
+	for key, value := range pv.Annotations {
+		if c.isValidAnnotation(key) {
+			applied[c.attributeFromValidAnnotation(key)] = value
+		}
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	actual, err := c.modifier.GetVolumeProperties(pv)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get properties of volume %q: %w", pv.Name, err)
+	}
+
+	drifted := make(map[string]string)
+	for key, value := range applied {
+		if actualValue, ok := actual[key]; ok && actualValue != value {
+			drifted[key] = actualValue
+		}
+	}
+	return drifted, nil
+}
+
+func (c *modifyController) handleDrift(pv *v1.PersistentVolume, drifted map[string]string) {
+	keys := make([]string, 0, len(drifted))
+	for key := range drifted {
+		keys = append(keys, key)
+		volumeDriftTotal.WithLabelValues(c.name, key).Inc()
+	}
+	sort.Strings(keys)
+
+	details := make([]string, 0, len(keys))
+	for _, key := range keys {
+		details = append(details, fmt.Sprintf("%s: applied %q, actual %q", key, pv.Annotations[fmt.Sprintf("%s/%s", c.name, key)], drifted[key]))
+	}
+	message := fmt.Sprintf("Volume %s differs from the last applied modification (%s)", pv.Name, strings.Join(details, ", "))
+	klog.InfoS("Volume drift detected", "pv", pv.Name, "drifted", drifted)
+
+	pvc := c.boundClaim(pv)
+	if pvc == nil {
+		c.eventRecorder.Event(pv, v1.EventTypeWarning, VolumeModificationDrift, message)
+		return
+	}
+	c.eventRecorder.Event(pvc, v1.EventTypeWarning, VolumeModificationDrift, message)
+
+	if c.driftReapply {
+		c.markDrifted(util.PVCKey(pvc))
+		c.claimQueue.Add(util.PVCKey(pvc))
+	}
+}
+
+// isModificationOutstanding returns true if a worker is modifying the PVC or
+// the driver is still applying its modification.
+func (c *modifyController) isModificationOutstanding(pvc *v1.PersistentVolumeClaim) bool {
+	return c.ifPVCModificationInProgress(pvc) || c.getOperation(util.PVCKey(pvc)) != nil
+}
+
+// boundClaim returns the PVC the PV is bound to, if it is known.
+func (c *modifyController) boundClaim(pv *v1.PersistentVolume) *v1.PersistentVolumeClaim {
+	if pv.Spec.ClaimRef == nil {
+		return nil
+	}
+	obj, exists, err := c.claims.GetByKey(pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name)
+	if err != nil || !exists {
+		return nil
+	}
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok || pvc.UID != pv.Spec.ClaimRef.UID {
+		return nil
+	}
+	return pvc
+}
+
+func (c *modifyController) markDrifted(key string) {
+	c.driftedClaimsMu.Lock()
+	defer c.driftedClaimsMu.Unlock()
+	c.driftedClaims[key] = struct{}{}
+}
+
+func (c *modifyController) isDrifted(key string) bool {
+	c.driftedClaimsMu.Lock()
+	defer c.driftedClaimsMu.Unlock()
+	_, ok := c.driftedClaims[key]
+	return ok
+}
+
+func (c *modifyController) clearDrifted(key string) {
+	c.driftedClaimsMu.Lock()
+	defer c.driftedClaimsMu.Unlock()
+	delete(c.driftedClaims, key)
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func waitForEvent(t *testing.T, kubeClient kubernetes.Interface, reason string, timeout time.Duration) *v1.Event {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		events, err := kubeClient.CoreV1().Events(v1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range events.Items {
+			if events.Items[i].Reason == reason {
+				return &events.Items[i]
+			}
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s event", reason)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestVolumeDrift(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	tests := []struct {
+		name          string
+		pvAnnotations map[string]string
+		properties    map[string]string
+		expected      map[string]string
+	}{
+		{
+			name:          "no applied modification",
+			pvAnnotations: map[string]string{},
+			properties:    map[string]string{"iops": "3000"},
+			expected:      nil,
+		},
+		{
+			name:          "properties match",
+			pvAnnotations: map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			properties:    map[string]string{"iops": "5000", "type": "gp3"},
+			expected:      map[string]string{},
+		},
+		{
+			name:          "property drifted",
+			pvAnnotations: map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/type": "io2"},
+			properties:    map[string]string{"iops": "3000", "type": "io2"},
+			expected:      map[string]string{"iops": "3000"},
+		},
+		{
+			name:          "unreported property is ignored",
+			pvAnnotations: map[string]string{"ebs.csi.aws.com/throughput": "500"},
+			properties:    map[string]string{"iops": "3000"},
+			expected:      map[string]string{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := csi.NewFakeClient(driverName, true, false)
+			client.SetVolumeProperties(tc.properties)
+			mod, err := modifier.NewFromClient(driverName, client, fake.NewClientset(), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctrl := newTestController(driverName)
+			ctrl.modifier = mod
+
+			pv := newTestPV("testPV", "", "", "", driverName)
+			pv.Annotations = tc.pvAnnotations
+
+			drifted, err := ctrl.volumeDrift(pv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(drifted) != len(tc.expected) {
+				t.Fatalf("unexpected drift: got %v, expected %v", drifted, tc.expected)
+			}
+			for key, value := range tc.expected {
+				if drifted[key] != value {
+					t.Fatalf("unexpected drift: got %v, expected %v", drifted, tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDrift_Reapply(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("drift-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "drift-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/iops"] = "5000"
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetVolumeProperties(map[string]string{"iops": "3000"})
+
+	ctrl := setupControllerWithClient(t, driverName, client, []Option{WithDriftCheck(20*time.Millisecond, true)}, pvc, pv)
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationDrift, 3*time.Second)
+	if !strings.Contains(event.Message, `iops: applied "5000", actual "3000"`) {
+		t.Fatalf("unexpected drift event message %q", event.Message)
+	}
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	if client.GetParams()["iops"] != "5000" {
+		t.Fatalf("expected desired iops to be reapplied, got %v", client.GetParams())
+	}
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "drift-pvc", "iops", ModificationStateSucceeded, 3*time.Second)
+}
+
+func TestCheckDrift_SkipsVolumesBeingModified(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("drift-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "drift-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/iops"] = "4000"
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetVolumeProperties(map[string]string{"iops": "3000"})
+	mod, err := modifier.NewFromClient(driverName, client, fake.NewClientset(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]func(ctrl *modifyController){
+		"worker modifying": func(ctrl *modifyController) {
+			ctrl.addPVCToInProgressList(pvc)
+		},
+		"driver applying": func(ctrl *modifyController) {
+			ctrl.operations["default/drift-pvc"] = &modificationOperation{id: "op-1", params: map[string]string{"iops": "5000"}}
+		},
+	}
+	for name, start := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := newTestController(driverName)
+			ctrl.modifier = mod
+			ctrl.driftReapply = true
+			ctrl.eventRecorder = record.NewFakeRecorder(10)
+			ctrl.volumes = cache.NewStore(cache.MetaNamespaceKeyFunc)
+			ctrl.claims = cache.NewStore(cache.MetaNamespaceKeyFunc)
+			ctrl.volumes.Add(pv)
+			ctrl.claims.Add(pvc)
+			start(ctrl)
+
+			ctrl.checkDrift()
+			if ctrl.isDrifted("default/drift-pvc") || ctrl.claimQueue.Len() != 0 {
+				t.Fatal("expected the drift check to skip a volume being modified")
+			}
+		})
+	}
+}
+
+func TestCheckDrift_NoReapply(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("drift-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "drift-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/iops"] = "5000"
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetVolumeProperties(map[string]string{"iops": "3000"})
+
+	ctrl := setupControllerWithClient(t, driverName, client, []Option{WithDriftCheck(20*time.Millisecond, false)}, pvc, pv)
+
+	waitForEvent(t, ctrl.kubeClient, VolumeModificationDrift, 3*time.Second)
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected drift not to be reapplied, got %d modify calls", client.GetModifyCallCount())
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	v1 "k8s.io/api/core/v1"
+)
+
+// isDryRun reports whether modifications of the PVC must only be validated,
+// either globally or through the "<driver-name>/dry-run" annotation.
+func (c *modifyController) isDryRun(pvc *v1.PersistentVolumeClaim) bool {
+	if c.dryRun {
+		return true
+	}
+	dryRun, _ := strconv.ParseBool(pvc.Annotations[fmt.Sprintf(AnnotationDryRunPattern, c.name)])
+	return dryRun
+}
+
+// validatePVC asks the driver whether it would accept the requested
+// modification and reports the outcome without modifying the volume.
+func (c *modifyController) validatePVC(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params, reqContext map[string]string) error {
+	secrets, err := getModifierSecrets(c.kubeClient, c.classes, pv, pvc)
+	if err != nil {
+		// The Secret may not exist yet, retry with backoff.
+		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationValidationFailed, "Dry run: cannot validate the modification of volume %s: %v", pv.Name, err)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, "dry run: "+err.Error())
+		return err
+	}
+
+	err = c.modifier.Validate(pv, params, reqContext, secrets)
+
+	var validationErr *csi.ValidationError
+	switch {
+	case err == nil:
+		c.eventRecorder.Eventf(pvc, v1.EventTypeNormal, VolumeModificationValidated, "Dry run: driver would accept the modification of volume %s", pv.Name)
+		c.updateModificationStatus(pvc, params, ModificationStateValidated, "")
+		return nil
+	case errors.As(err, &validationErr):
+		// The driver answered; retrying cannot change its verdict until the
+		// annotations are changed, which requeues the PVC.
+		c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationValidationFailed, "Dry run: driver would reject the modification of volume %s: %s", pv.Name, validationErr.Message)
+		c.updateModificationStatus(pvc, params, ModificationStateFailed, "dry run: "+validationErr.Message)
+		return nil
+	default:
+		c.eventRecorder.Event(pvc, v1.EventTypeWarning, VolumeModificationValidationFailed, err.Error())
+		return fmt.Errorf("validation of volume %q modification failed by modifier %q: %w", pvc.Name, c.name, err)
+	}
+}
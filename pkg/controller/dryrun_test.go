@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func setupDryRunController(t *testing.T, driverName string, client *csi.FakeClient, opts []Option, objects ...runtime.Object) *modifyController {
+	t.Helper()
+	k8sClient := fake.NewClientset(objects...)
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+
+	mod, err := modifier.NewFromClient(driverName, client, k8sClient, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := NewModifyController(driverName, mod, k8sClient, 0, factory,
+		workqueue.DefaultControllerRateLimiter(), false, opts...)
+
+	stopCh := make(chan struct{})
+	factory.Start(stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	go mc.Run(1, ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		close(stopCh)
+	})
+	return mc.(*modifyController)
+}
+
+func TestDryRun_Annotation(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("dry-run-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":    "5000",
+		"ebs.csi.aws.com/dry-run": "true",
+	})
+	pv := newTestPV("testPV", "dry-run-pvc", "default", "test-uid", driverName)
+	client := csi.NewFakeClient(driverName, true, false)
+
+	ctrl := setupDryRunController(t, driverName, client, nil, pvc, pv)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "dry-run-pvc", "iops", ModificationStateValidated, 3*time.Second)
+
+	if client.GetValidateCallCount() != 1 {
+		t.Fatalf("expected 1 validate call, got %d", client.GetValidateCallCount())
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call in dry run, got %d", client.GetModifyCallCount())
+	}
+	if _, ok := client.GetParams()["dry-run"]; ok {
+		t.Fatal("dry-run annotation should not be passed as param")
+	}
+
+	updatedPV, err := ctrl.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyNoAnnotationsOnPV(updatedPV.Annotations, driverName); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDryRun_Rejected(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("dry-run-reject-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "dry-run-reject-pvc", "default", "test-uid", driverName)
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetValidationError("iops requires io2")
+
+	ctrl := setupDryRunController(t, driverName, client, []Option{WithDryRun(true)}, pvc, pv)
+
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "dry-run-reject-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if !strings.Contains(status.Reason, "iops requires io2") {
+		t.Fatalf("unexpected failure reason %q", status.Reason)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call in dry run, got %d", client.GetModifyCallCount())
+	}
+}
+
+func TestDryRun_DisabledByAnnotation(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("no-dry-run-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":    "5000",
+		"ebs.csi.aws.com/dry-run": "false",
+	})
+	pv := newTestPV("testPV", "no-dry-run-pvc", "default", "test-uid", driverName)
+	client := csi.NewFakeClient(driverName, true, false)
+
+	setupDryRunController(t, driverName, client, nil, pvc, pv)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	if client.GetValidateCallCount() != 0 {
+		t.Fatalf("expected no validate call, got %d", client.GetValidateCallCount())
+	}
+}
+
+func TestNeedsProcessing_DryRunToggled(t *testing.T) {
+	ctrl := newTestController("ebs.csi.aws.com")
+	old := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		ResourceVersion: "1",
+		Annotations: map[string]string{
+			"ebs.csi.aws.com/iops":    "5000",
+			"ebs.csi.aws.com/dry-run": "true",
+		},
+	}}
+	new := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		ResourceVersion: "2",
+		Annotations: map[string]string{
+			"ebs.csi.aws.com/iops": "5000",
+		},
+	}}
+	if !ctrl.needsProcessing(old, new) {
+		t.Fatal("expected PVC to be processed after dry-run annotation removal")
+	}
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"context"
+	"sync"
+)
+
+// NewGroup returns a ModifyController that runs all controllers together,
+// e.g. the annotation-based controller and the VolumeModification
+// controller of the same driver.
+func NewGroup(controllers ...ModifyController) ModifyController {
+	return controllerGroup(controllers)
+}
+
+type controllerGroup []ModifyController
+
+// Run runs every controller with the given number of workers and returns
+// once all of them have stopped.
+func (g controllerGroup) Run(workers int, ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, c := range g {
+		wg.Add(1)
+		go func(c ModifyController) {
+			defer wg.Done()
+			c.Run(workers, ctx)
+		}(c)
+	}
+	wg.Wait()
+}
+
+func (g controllerGroup) HasSynced() bool {
+	for _, c := range g {
+		if !c.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
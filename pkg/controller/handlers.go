@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// eventHandlers holds the handlers a controller registered with the shared
+// informers. The informers outlive the controller, which is created again
+// each time leadership is acquired, so the handlers are removed once it
+// stops.
+type eventHandlers struct {
+	registrations []eventHandlerRegistration
+}
+
+type eventHandlerRegistration struct {
+	informer     cache.SharedInformer
+	registration cache.ResourceEventHandlerRegistration
+}
+
+// add registers handler with the informer, with the resync period of the
+// informer.
+func (h *eventHandlers) add(informer cache.SharedInformer, handler cache.ResourceEventHandler) {
+	registration, err := informer.AddEventHandler(handler)
+	h.track(informer, registration, err)
+}
+
+// addWithResyncPeriod registers handler with the informer.
+func (h *eventHandlers) addWithResyncPeriod(informer cache.SharedInformer, handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	registration, err := informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	h.track(informer, registration, err)
+}
+
+func (h *eventHandlers) track(informer cache.SharedInformer, registration cache.ResourceEventHandlerRegistration, err error) {
+	if err != nil {
+		klog.ErrorS(err, "Cannot register event handler")
+		return
+	}
+	h.registrations = append(h.registrations, eventHandlerRegistration{informer: informer, registration: registration})
+}
+
+// removeAll removes the registered handlers from their informers.
+func (h *eventHandlers) removeAll() {
+	for _, r := range h.registrations {
+		if err := r.informer.RemoveEventHandler(r.registration); err != nil {
+			klog.ErrorS(err, "Cannot remove event handler")
+		}
+	}
+	h.registrations = nil
+}
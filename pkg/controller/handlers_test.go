@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestEventHandlers_RemovedFromSharedInformer(t *testing.T) {
+	k8sClient := fake.NewClientset()
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+	informer := factory.Core().V1().PersistentVolumeClaims().Informer()
+
+	var removed, kept atomic.Int32
+	var handlers eventHandlers
+	handlers.add(informer, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { removed.Add(1) },
+	})
+	// Stands for the controller started when leadership is acquired again.
+	registration, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { kept.Add(1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced, registration.HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+
+	handlers.removeAll()
+	if len(handlers.registrations) != 0 {
+		t.Fatalf("expected registrations to be cleared, got %d", len(handlers.registrations))
+	}
+
+	if _, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Create(context.TODO(), newTestPVC("data", "default", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(3 * time.Second)
+	for kept.Load() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the PVC to be observed")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	if removed.Load() != 0 {
+		t.Fatalf("expected a removed handler not to be called, got %d calls", removed.Load())
+	}
+}
+
+func TestModifyController_RemovesHandlersWhenStopped(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	k8sClient := fake.NewClientset()
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+	mod, err := modifier.NewFromClient(driverName, csi.NewFakeClient(driverName, true, false), k8sClient, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := NewModifyController(driverName, mod, k8sClient, 0, factory,
+		workqueue.DefaultControllerRateLimiter(), true).(*modifyController)
+	if len(mc.handlers.registrations) == 0 {
+		t.Fatal("expected the controller to register event handlers")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		mc.Run(1, ctx)
+		close(done)
+	}()
+	waitForCacheSync(t, mc, 3*time.Second)
+	cancel()
+	<-done
+
+	if len(mc.handlers.registrations) != 0 {
+		t.Fatalf("expected the event handlers to be removed once stopped, got %d", len(mc.handlers.registrations))
+	}
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// modificationHistoryLimit bounds the number of records kept in the
+// modification history of a PV.
+const modificationHistoryLimit = 10
+
+// appendModificationHistory records on newPV the modification of the
+// parameters of pv requested by the PVC. A history that cannot be parsed is
+// replaced.
+func appendModificationHistory(driverName string, pv, newPV *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string, result, message string) {
+	history, err := util.GetModificationHistory(pv, driverName)
+	if err != nil {
+		klog.ErrorS(err, "Discarding modification history", "pv", pv.Name)
+		history = nil
+	}
+
+	before := make(map[string]string)
+	for key := range params {
+		if value, ok := pv.Annotations[fmt.Sprintf("%s/%s", driverName, key)]; ok {
+			before[key] = value
+		}
+	}
+	value, err := util.AppendModificationHistory(history, util.ModificationRecord{
+		Time:               time.Now().UTC().Truncate(time.Second),
+		Before:             before,
+		After:              params,
+		PVCResourceVersion: pvc.ResourceVersion,
+		Result:             result,
+		Message:            message,
+	}, modificationHistoryLimit)
+	if err != nil {
+		klog.ErrorS(err, "Cannot record modification history", "pv", pv.Name)
+		return
+	}
+	newPV.Annotations[fmt.Sprintf(AnnotationModificationHistoryPattern, driverName)] = value
+}
+
+// recordFailedModification appends a modification rejected by the driver to
+// the history of the PV.
+func (c *modifyController) recordFailedModification(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string, message string) {
+	newPV := pv.DeepCopy()
+	if newPV.Annotations == nil {
+		newPV.Annotations = make(map[string]string)
+	}
+	appendModificationHistory(c.name, pv, newPV, pvc, params, util.ModificationResultFailed, message)
+	if _, err := c.patchPV(pv, newPV, false); err != nil {
+		klog.ErrorS(err, "Cannot record failed modification in the history of the PV", "pv", pv.Name)
+	}
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// waitForHistory polls until the modification history of the PV has the
+// expected number of records or the timeout expires.
+func waitForHistory(t *testing.T, kubeClient kubernetes.Interface, driverName, pvName string, expected int, timeout time.Duration) []util.ModificationRecord {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		pv, err := kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		history, err := util.GetModificationHistory(pv, driverName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) >= expected {
+			return history
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d history records, got %+v", expected, history)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestHistory_RecordsSucceededModification(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("history-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/type": "gp3"})
+	pvc.ResourceVersion = "42"
+	pv := newTestPV("testPV", "history-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/iops"] = "3000"
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	history := waitForHistory(t, ctrl.kubeClient, driverName, "testPV", 1, 3*time.Second)
+	record := history[0]
+	if record.Result != util.ModificationResultSucceeded || record.PVCResourceVersion != "42" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if len(record.Before) != 1 || record.Before["iops"] != "3000" {
+		t.Errorf("expected previous values of the modified parameters, got %v", record.Before)
+	}
+	if record.After["iops"] != "5000" || record.After["type"] != "gp3" {
+		t.Errorf("expected requested values, got %v", record.After)
+	}
+	if record.Time.IsZero() {
+		t.Error("expected record time to be set")
+	}
+}
+
+func TestHistory_RecordsFailedModification(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("history-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "history-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, true)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	history := waitForHistory(t, ctrl.kubeClient, driverName, "testPV", 1, 3*time.Second)
+	if history[0].Result != util.ModificationResultFailed || history[0].Message != "modification failed" {
+		t.Errorf("unexpected record %+v", history[0])
+	}
+}
+
+func TestHistory_CollapsesRetriedFailures(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("history-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "history-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, true)
+	ctrl := setupRetryingController(t, driverName, client, 10*time.Millisecond, pvc, pv)
+
+	waitForModifyCount(t, client, 3, 3*time.Second)
+	deadline := time.After(3 * time.Second)
+	for {
+		history := waitForHistory(t, ctrl.kubeClient, driverName, "testPV", 1, 3*time.Second)
+		if len(history) != 1 {
+			t.Fatalf("expected retried failures to share a record, got %+v", history)
+		}
+		if history[0].Count >= 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for the record to count 3 failures, got %+v", history[0])
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestAppendModificationHistory_Bounded(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pv := newTestPV("testPV", "", "", "", driverName)
+	pvc := &v1.PersistentVolumeClaim{}
+	for i := 0; i < modificationHistoryLimit+5; i++ {
+		newPV := pv.DeepCopy()
+		appendModificationHistory(driverName, pv, newPV, pvc, map[string]string{"iops": "5000"}, util.ModificationResultSucceeded, "")
+		pv = newPV
+	}
+	history, err := util.GetModificationHistory(pv, driverName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != modificationHistoryLimit {
+		t.Fatalf("expected %d records, got %d", modificationHistoryLimit, len(history))
+	}
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics"
+)
+
+const metricsSubsystem = "volume_modifier"
+
+var (
+	volumeDriftTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "drift_detected_total",
+			Help:           "Number of times a driver-reported volume property differed from the last applied modification.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "parameter"},
+	)
+
+	modificationsStartedTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "modifications_started_total",
+			Help:           "Number of volume modifications sent to the CSI driver, per requested parameter.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "namespace", "parameter"},
+	)
+
+	modificationsSucceededTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "modifications_succeeded_total",
+			Help:           "Number of volume modifications applied and recorded on the PV, per requested parameter.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "namespace", "parameter"},
+	)
+
+	modificationsFailedTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "modifications_failed_total",
+			Help:           "Number of volume modifications that failed, per requested parameter.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "namespace", "parameter"},
+	)
+
+	modificationDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "modification_duration_seconds",
+			Help:           "Time from a changed modification annotation being observed on the PVC to the PV being updated with the applied parameters.",
+			Buckets:        metrics.ExponentialBuckets(1, 2, 16),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name"},
+	)
+
+	modificationsInProgress = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "modifications_in_progress",
+			Help:           "Number of PVCs whose modification is currently being processed by a worker or applied by the driver.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name"},
+	)
+
+	vacConflictRefusalsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "vac_conflict_refusals_total",
+			Help:           "Number of modifications refused because the PVC or PV has a VolumeAttributesClass associated.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "namespace"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// RegisterMetrics registers the controller and workqueue metrics to registry,
+// which is typically the registry of the CSI metrics manager served on
+// --http-endpoint. It must be called before the controller is created for the
+// workqueue metrics to be recorded.
+func RegisterMetrics(registry metrics.KubeRegistry) {
+	registerMetricsOnce.Do(func() {
+		registry.MustRegister(
+			volumeDriftTotal,
+			modificationsStartedTotal,
+			modificationsSucceededTotal,
+			modificationsFailedTotal,
+			modificationDuration,
+			modificationsInProgress,
+			vacConflictRefusalsTotal,
+		)
+		for _, m := range workqueueMetrics {
+			registry.MustRegister(m)
+		}
+		workqueue.SetProvider(workqueueMetricsProvider{})
+	})
+}
+
+// recordModifications increments counter once for every parameter of a
+// modification of pvc.
+func (c *modifyController) recordModifications(counter *metrics.CounterVec, pvc *v1.PersistentVolumeClaim, params map[string]string) {
+	for key := range params {
+		counter.WithLabelValues(c.name, pvc.Namespace, key).Inc()
+	}
+}
+
+// markRequested remembers when a changed modification request was observed
+// on the PVC with the given key.
+func (c *modifyController) markRequested(key string) {
+	c.requestTimesMu.Lock()
+	defer c.requestTimesMu.Unlock()
+	c.requestTimes[key] = time.Now()
+}
+
+// observeModificationDuration records the time elapsed since the request of
+// the PVC with the given key was observed, if it is known.
+func (c *modifyController) observeModificationDuration(key string) {
+	c.requestTimesMu.Lock()
+	defer c.requestTimesMu.Unlock()
+	if requestTime, ok := c.requestTimes[key]; ok {
+		modificationDuration.WithLabelValues(c.name).Observe(time.Since(requestTime).Seconds())
+		delete(c.requestTimes, key)
+	}
+}
+
+func (c *modifyController) forgetRequested(key string) {
+	c.requestTimesMu.Lock()
+	defer c.requestTimesMu.Unlock()
+	delete(c.requestTimes, key)
+}
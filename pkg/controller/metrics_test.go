@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func registerTestMetrics() {
+	RegisterMetrics(metrics.NewKubeRegistry())
+}
+
+func counterValue(t *testing.T, counter *metrics.CounterVec, labels ...string) float64 {
+	t.Helper()
+	value, err := testutil.GetCounterMetricValue(counter.WithLabelValues(labels...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func waitForCounterValue(t *testing.T, counter *metrics.CounterVec, expected float64, timeout time.Duration, labels ...string) {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		if counterValue(t, counter, labels...) >= expected {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for counter %v to reach %v, got %v", labels, expected, counterValue(t, counter, labels...))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestMetrics_SucceededModification(t *testing.T) {
+	registerTestMetrics()
+	driverName := "ebs.csi.aws.com"
+	labels := []string{driverName, "metrics-succeeded", "iops"}
+	started := counterValue(t, modificationsStartedTotal, labels...)
+	succeeded := counterValue(t, modificationsSucceededTotal, labels...)
+	observed, err := testutil.GetHistogramMetricCount(modificationDuration.WithLabelValues(driverName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pvc := newTestPVC("metrics-pvc", "metrics-succeeded", map[string]string{})
+	pvc.ResourceVersion = "1"
+	pv := newTestPV("testPV", "metrics-pvc", "metrics-succeeded", "test-uid", driverName)
+	mc, client := setupController(t, driverName, false, pvc, pv)
+
+	updated := pvc.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.Annotations["ebs.csi.aws.com/iops"] = "5000"
+	if _, err := mc.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	waitForCounterValue(t, modificationsSucceededTotal, succeeded+1, 3*time.Second, labels...)
+
+	if got := counterValue(t, modificationsStartedTotal, labels...); got != started+1 {
+		t.Errorf("expected started counter %v, got %v", started+1, got)
+	}
+	count, err := testutil.GetHistogramMetricCount(modificationDuration.WithLabelValues(driverName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != observed+1 {
+		t.Errorf("expected %d modification duration observations, got %d", observed+1, count)
+	}
+}
+
+func TestMetrics_FailedModification(t *testing.T) {
+	registerTestMetrics()
+	driverName := "ebs.csi.aws.com"
+	labels := []string{driverName, "metrics-failed", "iops"}
+	failed := counterValue(t, modificationsFailedTotal, labels...)
+
+	pvc := newTestPVC("metrics-pvc", "metrics-failed", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "metrics-pvc", "metrics-failed", "test-uid", driverName)
+	setupController(t, driverName, true, pvc, pv)
+
+	waitForCounterValue(t, modificationsFailedTotal, failed+1, 3*time.Second, labels...)
+}
+
+func TestMetrics_VACConflictRefusal(t *testing.T) {
+	registerTestMetrics()
+	driverName := "ebs.csi.aws.com"
+	vacName := "my-vac"
+	refusals := counterValue(t, vacConflictRefusalsTotal, driverName, "metrics-vac")
+
+	pvc := newTestPVC("metrics-pvc", "metrics-vac", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pvc.Spec.VolumeAttributesClassName = &vacName
+	pv := newTestPV("testPV", "metrics-pvc", "metrics-vac", "test-uid", driverName)
+	setupController(t, driverName, false, pvc, pv)
+
+	waitForCounterValue(t, vacConflictRefusalsTotal, refusals+1, 3*time.Second, driverName, "metrics-vac")
+}
+
+func TestMetrics_InProgressGauge(t *testing.T) {
+	registerTestMetrics()
+	driverName := "gauge.csi.aws.com"
+	ctrl := newTestController(driverName)
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pvc", Namespace: "default"},
+	}
+	gauge := func() float64 {
+		value, err := testutil.GetGaugeMetricValue(modificationsInProgress.WithLabelValues(driverName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	ctrl.addPVCToInProgressList(pvc)
+	ctrl.addPVCToInProgressList(pvc)
+	if got := gauge(); got != 1 {
+		t.Fatalf("expected 1 modification in progress, got %v", got)
+	}
+	ctrl.removePVCFromInProgressList(pvc)
+	ctrl.removePVCFromInProgressList(pvc)
+	if got := gauge(); got != 0 {
+		t.Fatalf("expected 0 modifications in progress, got %v", got)
+	}
+
+	// Modifications the driver is still applying are counted once.
+	ctrl.operations["default/test-pvc"] = &modificationOperation{id: "op-1"}
+	ctrl.addPVCToInProgressList(pvc)
+	ctrl.removePVCFromInProgressList(pvc)
+	if got := gauge(); got != 1 {
+		t.Fatalf("expected 1 asynchronous modification in progress, got %v", got)
+	}
+	ctrl.removeOperation("default/test-pvc")
+	if got := gauge(); got != 0 {
+		t.Fatalf("expected 0 modifications in progress, got %v", got)
+	}
+}
+
+func TestMetrics_WorkqueueProvider(t *testing.T) {
+	registerTestMetrics()
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "metrics-test")
+	defer queue.ShutDown()
+
+	queue.Add("default/pvc")
+	value, err := testutil.GetCounterMetricValue(workqueueAdds.WithLabelValues("metrics-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 1 {
+		t.Fatalf("expected 1 workqueue add, got %v", value)
+	}
+	depth, err := testutil.GetGaugeMetricValue(workqueueDepth.WithLabelValues("metrics-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth != 1 {
+		t.Fatalf("expected workqueue depth 1, got %v", depth)
+	}
+}
@@ -34,6 +34,20 @@ func (m *MockModifyController) EXPECT() *MockModifyControllerMockRecorder {
 	return m.recorder
 }
 
+// HasSynced mocks base method.
+func (m *MockModifyController) HasSynced() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSynced")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasSynced indicates an expected call of HasSynced.
+func (mr *MockModifyControllerMockRecorder) HasSynced() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSynced", reflect.TypeOf((*MockModifyController)(nil).HasSynced))
+}
+
 // Run mocks base method.
 func (m *MockModifyController) Run(arg0 int, arg1 context.Context) {
 	m.ctrl.T.Helper()
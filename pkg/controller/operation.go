@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// modificationOperation is a modification accepted by the driver that has
+// not completed yet.
+type modificationOperation struct {
+	id     string
+	params map[string]string
+}
+
+// persistedOperation is the JSON value of the AnnotationOperationPattern
+// annotation.
+type persistedOperation struct {
+	ID         string            `json:"id"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// addOperation tracks a modification accepted by the driver and records it
+// on the PVC, so that it is polled instead of reissued after a restart or
+// a change of leader. The PVC is returned updated.
+func (c *modifyController) addOperation(pvc *v1.PersistentVolumeClaim, op *modificationOperation) *v1.PersistentVolumeClaim {
+	c.operationsMu.Lock()
+	c.operations[util.PVCKey(pvc)] = op
+	c.operationsMu.Unlock()
+	c.updateInProgressGauge()
+
+	data, err := json.Marshal(persistedOperation{ID: op.id, Parameters: op.params})
+	if err != nil {
+		klog.ErrorS(err, "Failed to encode modification operation", "pvc", util.PVCKey(pvc), "operationID", op.id)
+		return pvc
+	}
+	newPVC := pvc.DeepCopy()
+	if newPVC.Annotations == nil {
+		newPVC.Annotations = make(map[string]string)
+	}
+	newPVC.Annotations[fmt.Sprintf(AnnotationOperationPattern, c.name)] = string(data)
+	updatedPVC, err := c.patchPVC(pvc, newPVC)
+	if err != nil {
+		klog.ErrorS(err, "Failed to record modification operation", "pvc", util.PVCKey(pvc), "operationID", op.id)
+		return pvc
+	}
+	return updatedPVC
+}
+
+func (c *modifyController) getOperation(key string) *modificationOperation {
+	c.operationsMu.Lock()
+	defer c.operationsMu.Unlock()
+	return c.operations[key]
+}
+
+func (c *modifyController) removeOperation(key string) {
+	c.operationsMu.Lock()
+	delete(c.operations, key)
+	c.operationsMu.Unlock()
+	c.updateInProgressGauge()
+}
+
+// finishOperation forgets the operation of the PVC and removes it from the
+// PVC, which is returned updated.
+func (c *modifyController) finishOperation(pvc *v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
+	c.removeOperation(util.PVCKey(pvc))
+
+	key := fmt.Sprintf(AnnotationOperationPattern, c.name)
+	if _, ok := pvc.Annotations[key]; !ok {
+		return pvc
+	}
+	newPVC := pvc.DeepCopy()
+	delete(newPVC.Annotations, key)
+	updatedPVC, err := c.patchPVC(pvc, newPVC)
+	if err != nil {
+		klog.ErrorS(err, "Failed to remove modification operation", "pvc", util.PVCKey(pvc))
+		return pvc
+	}
+	return updatedPVC
+}
+
+// restoreOperations tracks the operations recorded on the PVCs by a previous
+// instance of the controller. It must be called once the caches have synced
+// and before the workers start.
+func (c *modifyController) restoreOperations() {
+	key := fmt.Sprintf(AnnotationOperationPattern, c.name)
+	for _, obj := range c.claims.List() {
+		pvc, ok := obj.(*v1.PersistentVolumeClaim)
+		if !ok {
+			continue
+		}
+		value, ok := pvc.Annotations[key]
+		if !ok {
+			continue
+		}
+		var persisted persistedOperation
+		if err := json.Unmarshal([]byte(value), &persisted); err != nil || persisted.ID == "" {
+			klog.ErrorS(err, "Ignoring invalid modification operation", "pvc", util.PVCKey(pvc), "annotation", value)
+			continue
+		}
+		klog.InfoS("Resuming volume modification in progress", "pvc", util.PVCKey(pvc), "operationID", persisted.ID)
+		c.operationsMu.Lock()
+		c.operations[util.PVCKey(pvc)] = &modificationOperation{id: persisted.ID, params: persisted.Parameters}
+		c.operationsMu.Unlock()
+	}
+	c.updateInProgressGauge()
+}
+
+// pollModification asks the driver for the status of an outstanding
+// modification and requeues the PVC until it completes or fails.
+func (c *modifyController) pollModification(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, op *modificationOperation) error {
+	key := util.PVCKey(pvc)
+
+	status, err := c.modifier.GetModificationStatus(pv, op.id)
+	if err != nil {
+		// The operation is still outstanding, keep polling it whether or
+		// not failures are retried.
+		klog.ErrorS(err, "Cannot get status of volume modification", "pvc", key, "operationID", op.id)
+		c.claimQueue.AddAfter(key, c.pollInterval)
+		return nil
+	}
+
+	switch status.State {
+	case csi.ModificationInProgress:
+		klog.V(4).InfoS("Volume modification still in progress", "pvc", key, "operationID", op.id, "message", status.Message)
+		c.claimQueue.AddAfter(key, c.pollInterval)
+		return nil
+	case csi.ModificationFailed:
+		pvc = c.finishOperation(pvc)
+		c.recordModifications(modificationsFailedTotal, pvc, op.params)
+		c.eventRecorder.Event(pvc, v1.EventTypeWarning, VolumeModificationFailed, status.Message)
+		c.recordFailedModification(pv, pvc, op.params, status.Message)
+		c.updateFailedModificationStatus(pvc, op.params, errors.New(status.Message))
+		return fmt.Errorf("modification %q of volume %q failed by modifier %q: %s", op.id, pvc.Name, c.name, status.Message)
+	default:
+		pvc = c.finishOperation(pvc)
+		if err := c.completeModification(pv, pvc, op.params); err != nil {
+			return err
+		}
+		// Annotations changed while the driver applied the modification
+		// are requested next.
+		if !maps.Equal(c.paramsFromAnnotations(pvc), op.params) {
+			c.claimQueue.Add(key)
+		}
+		return nil
+	}
+}
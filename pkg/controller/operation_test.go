@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// setupAsyncController runs a controller against a driver completing
+// modifications asynchronously, reporting statuses in order.
+func setupAsyncController(t *testing.T, driverName string, statuses []csi.ModificationStatus, objects ...runtime.Object) (*modifyController, *csi.FakeClient) {
+	t.Helper()
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetAsyncModification("op-1", statuses...)
+	ctrl := setupControllerWithClient(t, driverName, client, []Option{WithModificationPollInterval(10 * time.Millisecond)}, objects...)
+	return ctrl, client
+}
+
+func waitForStatusCount(t *testing.T, client *csi.FakeClient, expected int, timeout time.Duration) {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		if client.GetStatusCallCount() >= expected {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for status call count %d, got %d", expected, client.GetStatusCallCount())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestAsyncModification_Completes(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("async-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "async-pvc", "default", "test-uid", driverName)
+
+	ctrl, client := setupAsyncController(t, driverName, []csi.ModificationStatus{
+		{State: csi.ModificationInProgress, Message: "optimizing"},
+		{State: csi.ModificationInProgress, Message: "optimizing"},
+		{State: csi.ModificationCompleted},
+	}, pvc, pv)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "async-pvc", "iops", ModificationStateSucceeded, 3*time.Second)
+
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected 1 modify call, got %d", client.GetModifyCallCount())
+	}
+	if client.GetStatusCallCount() < 3 {
+		t.Fatalf("expected the driver to be polled until completion, got %d status calls", client.GetStatusCallCount())
+	}
+
+	updatedPV, err := ctrl.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedPV.Annotations["ebs.csi.aws.com/iops"] != "5000" {
+		t.Fatalf("expected PV annotation iops=5000 after completion, got %q", updatedPV.Annotations["ebs.csi.aws.com/iops"])
+	}
+	if ctrl.getOperation("default/async-pvc") != nil {
+		t.Fatal("expected completed operation to be forgotten")
+	}
+}
+
+func TestAsyncModification_Fails(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("async-fail-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "async-fail-pvc", "default", "test-uid", driverName)
+
+	ctrl, client := setupAsyncController(t, driverName, []csi.ModificationStatus{
+		{State: csi.ModificationInProgress},
+		{State: csi.ModificationFailed, Message: "volume is in optimizing state"},
+	}, pvc, pv)
+
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "async-fail-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if status.Reason != "volume is in optimizing state" {
+		t.Fatalf("unexpected failure reason %q", status.Reason)
+	}
+
+	updatedPV, err := ctrl.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyNoAnnotationsOnPV(updatedPV.Annotations, driverName); err != nil {
+		t.Fatal(err)
+	}
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected 1 modify call, got %d", client.GetModifyCallCount())
+	}
+}
+
+func TestAsyncModification_NotReissuedWhileOutstanding(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("async-wait-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "async-wait-pvc", "default", "test-uid", driverName)
+
+	ctrl, client := setupAsyncController(t, driverName, []csi.ModificationStatus{
+		{State: csi.ModificationInProgress},
+	}, pvc, pv)
+
+	waitForStatusCount(t, client, 3, 3*time.Second)
+
+	// Resync the PVC while the driver is still working on it.
+	ctrl.addPVC(pvc)
+	waitForStatusCount(t, client, 5, 3*time.Second)
+
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected modification not to be reissued while in progress, got %d modify calls", client.GetModifyCallCount())
+	}
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "async-wait-pvc", "iops", ModificationStateInProgress, 3*time.Second)
+}
+
+func TestAsyncModification_ChangedWhileOutstanding(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("async-change-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "async-change-pvc", "default", "test-uid", driverName)
+
+	ctrl, client := setupAsyncController(t, driverName, []csi.ModificationStatus{
+		{State: csi.ModificationInProgress},
+	}, pvc, pv)
+	waitForStatusCount(t, client, 1, 3*time.Second)
+
+	// The user asks for other values while the driver is still working.
+	updated, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "async-change-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated.Annotations["ebs.csi.aws.com/iops"] = "6000"
+	if _, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.After(3 * time.Second)
+	for {
+		obj, _, _ := ctrl.claims.GetByKey("default/async-change-pvc")
+		if obj.(*v1.PersistentVolumeClaim).Annotations["ebs.csi.aws.com/iops"] == "6000" {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the PVC update to be observed")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	client.SetAsyncModification("op-1", csi.ModificationStatus{State: csi.ModificationCompleted})
+
+	waitForModifyCount(t, client, 2, 3*time.Second)
+	if got := client.GetParams()["iops"]; got != "6000" {
+		t.Fatalf("expected the changed annotations to be requested once the operation completed, got %q", got)
+	}
+}
+
+func TestAsyncModification_ResumedAfterRestart(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("async-resume-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":      "5000",
+		"ebs.csi.aws.com/operation": `{"id":"op-1","parameters":{"iops":"5000"}}`,
+	})
+	pv := newTestPV("testPV", "async-resume-pvc", "default", "test-uid", driverName)
+
+	ctrl, client := setupAsyncController(t, driverName, []csi.ModificationStatus{
+		{State: csi.ModificationInProgress},
+		{State: csi.ModificationCompleted},
+	}, pvc, pv)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "async-resume-pvc", "iops", ModificationStateSucceeded, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected the operation started before the restart to be polled instead of reissued, got %d modify calls", client.GetModifyCallCount())
+	}
+	updatedPVC, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "async-resume-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updatedPVC.Annotations["ebs.csi.aws.com/operation"]; ok {
+		t.Fatal("expected the completed operation to be removed from the PVC")
+	}
+}
+
+func TestAsyncModification_PolledAfterStatusError(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("async-error-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "async-error-pvc", "default", "test-uid", driverName)
+
+	ctrl, client := setupAsyncController(t, driverName, []csi.ModificationStatus{
+		{State: csi.ModificationCompleted},
+	}, pvc, pv)
+	client.SetStatusError(errors.New("driver is busy"))
+
+	waitForStatusCount(t, client, 2, 3*time.Second)
+	updatedPVC, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "async-error-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedPVC.Annotations["ebs.csi.aws.com/operation"] != `{"id":"op-1","parameters":{"iops":"5000"}}` {
+		t.Fatalf("expected the outstanding operation to be recorded on the PVC, got %q", updatedPVC.Annotations["ebs.csi.aws.com/operation"])
+	}
+
+	client.SetStatusError(nil)
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "async-error-pvc", "iops", ModificationStateSucceeded, 3*time.Second)
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected 1 modify call, got %d", client.GetModifyCallCount())
+	}
+}
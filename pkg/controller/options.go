@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	defaultModificationPollInterval = 15 * time.Second
+	defaultShutdownTimeout          = 30 * time.Second
+)
+
+// Option configures optional behaviour of a ModifyController.
+type Option func(*modifyController)
+
+// WithModificationPollInterval sets how often the driver is polled for the
+// status of modifications that complete asynchronously.
+func WithModificationPollInterval(interval time.Duration) Option {
+	return func(c *modifyController) {
+		c.pollInterval = interval
+	}
+}
+
+// WithDryRun makes the controller validate every requested modification with
+// the driver instead of applying it.
+func WithDryRun(dryRun bool) Option {
+	return func(c *modifyController) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithRequestContextPassThrough passes the given labels and annotations of the
+// PVC to the driver in the context of every modification, under the
+// ContextKeyPVCLabelPrefix and ContextKeyPVCAnnotationPrefix prefixes.
+func WithRequestContextPassThrough(labels, annotations []string) Option {
+	return func(c *modifyController) {
+		c.contextLabels = labels
+		c.contextAnnotations = annotations
+	}
+}
+
+// DriverAvailability reports whether the CSI driver can be reached, as
+// client.ResilientClient does.
+type DriverAvailability interface {
+	// WaitAvailable blocks until the driver is available or ctx is done.
+	WaitAvailable(ctx context.Context) error
+}
+
+// WithDriverAvailability pauses the workers while the driver is not
+// available, leaving the PVCs queued until it is.
+func WithDriverAvailability(driver DriverAvailability) Option {
+	return func(c *modifyController) {
+		c.driver = driver
+	}
+}
+
+// WithDriftCheck periodically compares the properties reported by the driver
+// with the last applied modification of every volume. When reapply is set,
+// drifted volumes are modified again to the desired state.
+func WithDriftCheck(interval time.Duration, reapply bool) Option {
+	return func(c *modifyController) {
+		c.driftCheckInterval = interval
+		c.driftReapply = reapply
+	}
+}
+
+// WithModificationCooldown sets the minimum time between two modifications of
+// the same volume. The driver-advertised cooldown is used if it is longer.
+func WithModificationCooldown(cooldown time.Duration) Option {
+	return func(c *modifyController) {
+		c.cooldown = cooldown
+	}
+}
+
+// WithShutdownTimeout bounds how long the controller waits for in-flight
+// modifications to finish after it is stopped.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(c *modifyController) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+// WithModificationQuotas refuses modifications that would exceed a
+// VolumeModificationQuota of the namespace of the PVC. The quotas are watched
+// through vmInformerFactory, which is started.
+func WithModificationQuotas(vmInformerFactory externalversions.SharedInformerFactory) Option {
+	return func(c *modifyController) {
+		informer := vmInformerFactory.Volumemodifier().V1alpha1().VolumeModificationQuotas()
+		c.quotas = informer.Lister()
+		c.quotaInformer = informer.Informer()
+		vmInformerFactory.Start(wait.NeverStop)
+	}
+}
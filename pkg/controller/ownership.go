@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+const (
+	kindVolumeModification     = "VolumeModification"
+	kindVolumeModificationPlan = "VolumeModificationPlan"
+)
+
+// requester returns the kind and name of the VolumeModificationPlan or
+// VolumeModification that requested the modification of the PVC, or empty
+// strings if it was requested through the annotations.
+func requester(driverName string, pvc *v1.PersistentVolumeClaim) (string, string) {
+	if plan, ok := pvc.Annotations[fmt.Sprintf(AnnotationPlanPattern, driverName)]; ok {
+		return kindVolumeModificationPlan, plan
+	}
+	if vm, ok := pvc.Annotations[fmt.Sprintf(AnnotationVolumeModificationPattern, driverName)]; ok {
+		return kindVolumeModification, vm
+	}
+	return "", ""
+}
+
+// claimOwners finds the objects of a driver that own the modification of a
+// PVC, so that a VolumeModification and a VolumeModificationPlan never
+// overwrite each other's parameters.
+type claimOwners struct {
+	name  string
+	vms   listers.VolumeModificationLister
+	plans listers.VolumeModificationPlanLister
+}
+
+// active returns the VolumeModification or VolumeModificationPlan that
+// requested the modification of the PVC, as "<kind>/<name>", while it is
+// still in progress.
+func (o claimOwners) active(pvc *v1.PersistentVolumeClaim) (string, error) {
+	kind, name := requester(o.name, pvc)
+	switch kind {
+	case kindVolumeModificationPlan:
+		plan, err := o.plans.Get(name)
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("cannot get VolumeModificationPlan %s: %w", name, err)
+		}
+		if plan.Spec.DriverName != o.name || isPlanFinished(plan) {
+			return "", nil
+		}
+	case kindVolumeModification:
+		vm, err := o.vms.VolumeModifications(pvc.Namespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("cannot get VolumeModification %s/%s: %w", pvc.Namespace, name, err)
+		}
+		if isVolumeModificationFinished(vm) || isVolumeModificationFailed(vm) {
+			return "", nil
+		}
+	default:
+		return "", nil
+	}
+	return kind + "/" + name, nil
+}
+
+// isVolumeModificationFailed returns true if the current generation of the
+// VolumeModification failed with an error that is not retried.
+func isVolumeModificationFailed(vm *v1alpha1.VolumeModification) bool {
+	condition := meta.FindStatusCondition(vm.Status.Conditions, v1alpha1.ConditionApplied)
+	return condition != nil && condition.ObservedGeneration == vm.Generation && condition.Reason == v1alpha1.ReasonFailed
+}
@@ -0,0 +1,542 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// NewVolumeModificationPlanController returns a controller that rolls the
+// parameters of VolumeModificationPlan objects out to the PVCs they select.
+// It does not call the driver itself: it sets the "<driver-name>/<key>"
+// annotations of at most spec.maxConcurrency PVCs at a time, which queues
+// them in the ModifyController of the same driver, and follows their
+// progress through the "<driver-name>/<key>-status" annotations.
+func NewVolumeModificationPlanController(
+	name string,
+	kubeClient kubernetes.Interface,
+	client versioned.Interface,
+	resyncPeriod time.Duration,
+	informerFactory informers.SharedInformerFactory,
+	vmInformerFactory externalversions.SharedInformerFactory,
+	rateLimiter workqueue.RateLimiter,
+) ModifyController {
+	pvInformer := informerFactory.Core().V1().PersistentVolumes()
+	pvcInformer := informerFactory.Core().V1().PersistentVolumeClaims()
+	nsInformer := informerFactory.Core().V1().Namespaces()
+	planInformer := vmInformerFactory.Volumemodifier().V1alpha1().VolumeModificationPlans()
+	vmInformer := vmInformerFactory.Volumemodifier().V1alpha1().VolumeModifications()
+	queue := workqueue.NewNamedRateLimitingQueue(rateLimiter, fmt.Sprintf("%s-volume-modification-plan", name))
+
+	eventScheme := runtime.NewScheme()
+	utilruntime.Must(kubescheme.AddToScheme(eventScheme))
+	utilruntime.Must(v1alpha1.AddToScheme(eventScheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(v1.NamespaceAll)})
+	eventRecorder := eventBroadcaster.NewRecorder(eventScheme, v1.EventSource{Component: fmt.Sprintf("volume-modifier-for-k8s-%s", name)})
+
+	ctrl := &planController{
+		name:          name,
+		kubeClient:    kubeClient,
+		client:        client,
+		queue:         queue,
+		eventRecorder: eventRecorder,
+		lister:        planInformer.Lister(),
+		owners:        claimOwners{name: name, vms: vmInformer.Lister(), plans: planInformer.Lister()},
+		namespaces:    nsInformer.Lister(),
+		planSynced:    planInformer.Informer().HasSynced,
+		vmSynced:      vmInformer.Informer().HasSynced,
+		pvSynced:      pvInformer.Informer().HasSynced,
+		pvcSynced:     pvcInformer.Informer().HasSynced,
+		nsSynced:      nsInformer.Informer().HasSynced,
+		volumes:       pvInformer.Informer().GetStore(),
+		claims:        pvcInformer.Informer().GetStore(),
+		admitted:      make(map[string]admittedClaim),
+	}
+
+	ctrl.handlers.addWithResyncPeriod(planInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.enqueue,
+		UpdateFunc: func(_, new interface{}) { ctrl.enqueue(new) },
+	}, resyncPeriod)
+	// The progress of a plan changes with the status annotations of its PVCs.
+	// Both versions of an updated PVC are matched, as its labels may have
+	// moved it in or out of a plan.
+	ctrl.handlers.add(pvcInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { ctrl.enqueueForClaims(obj) },
+		UpdateFunc: func(old, new interface{}) { ctrl.enqueueForClaims(old, new) },
+		DeleteFunc: func(obj interface{}) { ctrl.enqueueForClaims(obj) },
+	})
+	// PVCs owned by a VolumeModification are admitted once it finishes.
+	ctrl.handlers.add(vmInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, new interface{}) { ctrl.enqueueForVolumeModification(new) },
+		DeleteFunc: ctrl.enqueueForVolumeModification,
+	})
+	informerFactory.Start(wait.NeverStop)
+	vmInformerFactory.Start(wait.NeverStop)
+
+	return ctrl
+}
+
+type planController struct {
+	name          string
+	kubeClient    kubernetes.Interface
+	client        versioned.Interface
+	queue         workqueue.RateLimitingInterface
+	eventRecorder record.EventRecorder
+	lister        listers.VolumeModificationPlanLister
+	owners        claimOwners
+	namespaces    corelisters.NamespaceLister
+	planSynced    cache.InformerSynced
+	vmSynced      cache.InformerSynced
+	pvSynced      cache.InformerSynced
+	pvcSynced     cache.InformerSynced
+	nsSynced      cache.InformerSynced
+	handlers      eventHandlers
+
+	volumes cache.Store
+	claims  cache.Store
+
+	// admitted holds the PVCs patched by this controller until the informer
+	// observes the patch, so that the next sync does not admit more PVCs
+	// than allowed. It is only used by the single worker.
+	admitted map[string]admittedClaim
+}
+
+type admittedClaim struct {
+	previousResourceVersion string
+	pvc                     *v1.PersistentVolumeClaim
+}
+
+func (c *planController) Run(workers int, ctx context.Context) {
+	defer c.queue.ShutDown()
+	defer c.handlers.removeAll()
+
+	klog.InfoS("Starting VolumeModificationPlan controller", "name", c.name)
+	defer klog.InfoS("Shutting down VolumeModificationPlan controller", "name", c.name)
+
+	stopCh := ctx.Done()
+	if !cache.WaitForCacheSync(stopCh, c.planSynced, c.vmSynced, c.pvSynced, c.pvcSynced, c.nsSynced) {
+		klog.Errorf("Cannot sync VolumeModificationPlan, VolumeModification, pv, pvc or namespace caches")
+		return
+	}
+
+	// A single worker, so that two syncs of the same plan never admit
+	// PVCs concurrently. The modifications themselves are run by the
+	// workers of the ModifyController.
+	go wait.Until(c.syncPlans, 0, stopCh)
+
+	<-stopCh
+}
+
+func (c *planController) HasSynced() bool {
+	return c.planSynced() && c.vmSynced() && c.pvSynced() && c.pvcSynced() && c.nsSynced()
+}
+
+func (c *planController) enqueue(obj interface{}) {
+	key, err := getObjectKeys(obj)
+	if err != nil {
+		klog.ErrorS(err, "unable to add obj to VolumeModificationPlan queue")
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueForClaims queues the plans of this driver selecting any of the
+// given PVCs.
+func (c *planController) enqueueForClaims(objs ...interface{}) {
+	var claims []*v1.PersistentVolumeClaim
+	for _, obj := range objs {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		if pvc, ok := obj.(*v1.PersistentVolumeClaim); ok {
+			claims = append(claims, pvc)
+		}
+	}
+	if len(claims) == 0 {
+		return
+	}
+
+	plans, err := c.lister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "cannot list VolumeModificationPlans")
+		return
+	}
+	for _, plan := range plans {
+		if plan.Spec.DriverName != c.name || isPlanFinished(plan) {
+			continue
+		}
+		selector, nsSelector, err := planSelectors(plan)
+		if err != nil {
+			// Reported when the plan is synced.
+			continue
+		}
+		for _, pvc := range claims {
+			if c.selects(selector, nsSelector, pvc) {
+				c.enqueue(plan)
+				break
+			}
+		}
+	}
+}
+
+// enqueueForVolumeModification queues the plans selecting the PVC of the
+// VolumeModification.
+func (c *planController) enqueueForVolumeModification(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	vm, ok := obj.(*v1alpha1.VolumeModification)
+	if !ok {
+		return
+	}
+	pvc, exists, err := c.claims.GetByKey(fmt.Sprintf("%s/%s", vm.Namespace, vm.Spec.PersistentVolumeClaimName))
+	if err != nil || !exists {
+		return
+	}
+	c.enqueueForClaims(pvc)
+}
+
+func (c *planController) syncPlans() {
+	key, quit := c.queue.Get()
+	if quit {
+		return
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncPlan(key.(string)); err != nil {
+		klog.ErrorS(err, "error syncing VolumeModificationPlan", "key", key)
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+}
+
+func (c *planController) syncPlan(key string) error {
+	plan, err := c.lister.Get(key)
+	if apierrors.IsNotFound(err) {
+		klog.InfoS("VolumeModificationPlan is deleted or does not exist", "name", key)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get VolumeModificationPlan %s: %w", key, err)
+	}
+	if plan.Spec.DriverName != c.name || isPlanFinished(plan) {
+		return nil
+	}
+
+	claims, err := c.selectClaims(plan)
+	if err != nil {
+		return err
+	}
+
+	status := v1alpha1.VolumeModificationPlanStatus{
+		ObservedGeneration: plan.Generation,
+		Claims:             make([]v1alpha1.PlanClaimStatus, 0, len(claims)),
+	}
+	var pending []*v1.PersistentVolumeClaim
+	var waiting int
+	for _, claim := range claims {
+		state, message := c.claimState(plan, claim.pvc, claim.pv)
+		switch state {
+		case v1alpha1.PlanClaimPending:
+			// PVCs still being modified by another object are admitted once
+			// it finishes, rather than overwriting its parameters.
+			owner, err := c.owners.active(claim.pvc)
+			if err != nil {
+				return err
+			}
+			if owner != "" && owner != kindVolumeModificationPlan+"/"+plan.Name {
+				message = fmt.Sprintf("Waiting for %s to finish", owner)
+				waiting++
+				break
+			}
+			pending = append(pending, claim.pvc)
+		case v1alpha1.PlanClaimInProgress:
+			status.InProgress++
+		case v1alpha1.PlanClaimSucceeded:
+			status.Succeeded++
+		case v1alpha1.PlanClaimFailed:
+			status.Failed++
+		}
+		status.Claims = append(status.Claims, v1alpha1.PlanClaimStatus{
+			Namespace: claim.pvc.Namespace,
+			Name:      claim.pvc.Name,
+			State:     state,
+			Message:   message,
+		})
+	}
+
+	switch {
+	case status.Failed >= planFailureThreshold(plan):
+		status.Phase = v1alpha1.PlanPhaseFailed
+	case len(pending) == 0 && waiting == 0 && status.InProgress == 0:
+		status.Phase = v1alpha1.PlanPhaseCompleted
+	case plan.Spec.Paused:
+		status.Phase = v1alpha1.PlanPhasePaused
+	default:
+		status.Phase = v1alpha1.PlanPhaseRunning
+		for _, pvc := range pending {
+			if status.InProgress >= planMaxConcurrency(plan) {
+				break
+			}
+			if err := c.admitClaim(plan, pvc); err != nil {
+				return err
+			}
+			status.InProgress++
+			setPlanClaimState(&status, pvc, v1alpha1.PlanClaimInProgress)
+		}
+	}
+	for _, claim := range status.Claims {
+		if claim.State == v1alpha1.PlanClaimPending {
+			status.Pending++
+		}
+	}
+
+	return c.updateStatus(plan, status)
+}
+
+type planClaim struct {
+	pvc *v1.PersistentVolumeClaim
+	pv  *v1.PersistentVolume
+}
+
+// selectClaims returns the bound PVCs selected by the plan whose volume is
+// handled by this driver, sorted by namespace and name.
+func (c *planController) selectClaims(plan *v1alpha1.VolumeModificationPlan) ([]planClaim, error) {
+	selector, nsSelector, err := planSelectors(plan)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims []planClaim
+	for _, obj := range c.claims.List() {
+		pvc, ok := obj.(*v1.PersistentVolumeClaim)
+		if !ok {
+			continue
+		}
+		pvc = c.latest(pvc)
+		if !c.selects(selector, nsSelector, pvc) {
+			continue
+		}
+		if pvc.Status.Phase != v1.ClaimBound || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		volumeObj, exists, err := c.volumes.GetByKey(pvc.Spec.VolumeName)
+		if err != nil || !exists {
+			continue
+		}
+		pv, ok := volumeObj.(*v1.PersistentVolume)
+		if !ok || !ownsVolume(c.name, pv) {
+			continue
+		}
+		claims = append(claims, planClaim{pvc: pvc, pv: pv})
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		return util.PVCKey(claims[i].pvc) < util.PVCKey(claims[j].pvc)
+	})
+	return claims, nil
+}
+
+// selects returns true if the labels of the PVC and of its namespace match
+// the selectors of a plan.
+func (c *planController) selects(selector, nsSelector labels.Selector, pvc *v1.PersistentVolumeClaim) bool {
+	if !selector.Matches(labels.Set(pvc.Labels)) {
+		return false
+	}
+	if nsSelector.Empty() {
+		return true
+	}
+	ns, err := c.namespaces.Get(pvc.Namespace)
+	return err == nil && nsSelector.Matches(labels.Set(ns.Labels))
+}
+
+// latest returns the PVC patched by admitClaim until the cache observes the
+// patch.
+func (c *planController) latest(pvc *v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
+	key := util.PVCKey(pvc)
+	admitted, ok := c.admitted[key]
+	if !ok {
+		return pvc
+	}
+	planKey := fmt.Sprintf(AnnotationPlanPattern, c.name)
+	if admitted.previousResourceVersion != pvc.ResourceVersion || pvc.Annotations[planKey] == admitted.pvc.Annotations[planKey] {
+		delete(c.admitted, key)
+		return pvc
+	}
+	return admitted.pvc
+}
+
+// claimState derives the progress of a PVC from the annotations maintained
+// by the ModifyController: the PV records the parameters last applied and the
+// PVC records the status of the last requested modification. Only failures
+// that are not retried count as failed.
+func (c *planController) claimState(plan *v1alpha1.VolumeModificationPlan, pvc *v1.PersistentVolumeClaim, pv *v1.PersistentVolume) (string, string) {
+	applied, requested := true, true
+	for key, value := range plan.Spec.Parameters {
+		annotation := fmt.Sprintf("%s/%s", c.name, key)
+		if pv.Annotations[annotation] != value {
+			applied = false
+		}
+		if pvc.Annotations[annotation] != value {
+			requested = false
+		}
+	}
+	if applied {
+		return v1alpha1.PlanClaimSucceeded, ""
+	}
+	if !requested {
+		return v1alpha1.PlanClaimPending, ""
+	}
+	keys := make([]string, 0, len(plan.Spec.Parameters))
+	for key := range plan.Spec.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	message := ""
+	for _, key := range keys {
+		var status modificationStatus
+		if err := json.Unmarshal([]byte(pvc.Annotations[fmt.Sprintf(AnnotationStatusPrefixPattern, c.name, key)]), &status); err != nil ||
+			status.Value != plan.Spec.Parameters[key] || status.State != ModificationStateFailed {
+			continue
+		}
+		if status.Failure == FailureRetryable {
+			// The ModifyController retries the modification with backoff,
+			// so it does not count towards the failure threshold.
+			message = fmt.Sprintf("Retrying modification of volume %s: %s", pv.Name, status.Reason)
+			continue
+		}
+		return v1alpha1.PlanClaimFailed, status.Reason
+	}
+	return v1alpha1.PlanClaimInProgress, message
+}
+
+// admitClaim requests the modification of the PVC by setting the parameters
+// of the plan as its "<driver-name>/<key>" annotations.
+func (c *planController) admitClaim(plan *v1alpha1.VolumeModificationPlan, pvc *v1.PersistentVolumeClaim) error {
+	newPVC := pvc.DeepCopy()
+	if newPVC.Annotations == nil {
+		newPVC.Annotations = make(map[string]string)
+	}
+	for key, value := range plan.Spec.Parameters {
+		newPVC.Annotations[fmt.Sprintf("%s/%s", c.name, key)] = value
+	}
+	newPVC.Annotations[fmt.Sprintf(AnnotationPlanPattern, c.name)] = plan.Name
+	delete(newPVC.Annotations, fmt.Sprintf(AnnotationVolumeModificationPattern, c.name))
+
+	patchBytes, err := util.GetPatchData(pvc, newPVC)
+	if err != nil {
+		return fmt.Errorf("can't patch PVC %s as patch data generation failed: %v", util.PVCKey(pvc), err)
+	}
+	updatedPVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).
+		Patch(context.TODO(), pvc.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("can't patch PVC %s with %v", util.PVCKey(pvc), err)
+	}
+	// The shared cache is left to the informer, as the ModifyController
+	// only queues PVCs whose annotations changed between two updates.
+	c.admitted[util.PVCKey(pvc)] = admittedClaim{previousResourceVersion: pvc.ResourceVersion, pvc: updatedPVC}
+	klog.InfoS("Requested modification of PVC by VolumeModificationPlan", "pvc", util.PVCKey(pvc), "plan", plan.Name)
+	return nil
+}
+
+func (c *planController) updateStatus(plan *v1alpha1.VolumeModificationPlan, status v1alpha1.VolumeModificationPlanStatus) error {
+	if equality.Semantic.DeepEqual(plan.Status, status) {
+		return nil
+	}
+	newPlan := plan.DeepCopy()
+	newPlan.Status = status
+	if _, err := c.client.VolumemodifierV1alpha1().VolumeModificationPlans().UpdateStatus(context.TODO(), newPlan, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("cannot update status of VolumeModificationPlan %s: %w", plan.Name, err)
+	}
+
+	if plan.Status.Phase != status.Phase {
+		switch status.Phase {
+		case v1alpha1.PlanPhaseCompleted:
+			c.eventRecorder.Eventf(plan, v1.EventTypeNormal, VolumeModificationPlanCompleted, "Modified %d volumes", status.Succeeded)
+		case v1alpha1.PlanPhaseFailed:
+			c.eventRecorder.Eventf(plan, v1.EventTypeWarning, VolumeModificationPlanFailed, "Stopped after %d failed volumes", status.Failed)
+		}
+	}
+	return nil
+}
+
+// isPlanFinished returns true once the current generation of the plan has
+// completed or reached its failure threshold.
+func isPlanFinished(plan *v1alpha1.VolumeModificationPlan) bool {
+	return plan.Status.ObservedGeneration == plan.Generation &&
+		(plan.Status.Phase == v1alpha1.PlanPhaseCompleted || plan.Status.Phase == v1alpha1.PlanPhaseFailed)
+}
+
+func planMaxConcurrency(plan *v1alpha1.VolumeModificationPlan) int32 {
+	if plan.Spec.MaxConcurrency < 1 {
+		return 1
+	}
+	return plan.Spec.MaxConcurrency
+}
+
+func planFailureThreshold(plan *v1alpha1.VolumeModificationPlan) int32 {
+	if plan.Spec.FailureThreshold < 1 {
+		return 1
+	}
+	return plan.Spec.FailureThreshold
+}
+
+func setPlanClaimState(status *v1alpha1.VolumeModificationPlanStatus, pvc *v1.PersistentVolumeClaim, state string) {
+	for i := range status.Claims {
+		if status.Claims[i].Namespace == pvc.Namespace && status.Claims[i].Name == pvc.Name {
+			status.Claims[i].State = state
+			return
+		}
+	}
+}
+
+// planSelectors returns the PVC and namespace selectors of the plan.
+func planSelectors(plan *v1alpha1.VolumeModificationPlan) (labels.Selector, labels.Selector, error) {
+	selector, err := labelSelectorAsSelector(plan.Spec.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid selector of VolumeModificationPlan %s: %w", plan.Name, err)
+	}
+	nsSelector, err := labelSelectorAsSelector(plan.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid namespace selector of VolumeModificationPlan %s: %w", plan.Name, err)
+	}
+	return selector, nsSelector, nil
+}
+
+// labelSelectorAsSelector converts a selector of the plan, treating a nil
+// selector as selecting everything.
+func labelSelectorAsSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
@@ -0,0 +1,460 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	vmfake "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/fake"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const planDriverName = "ebs.csi.aws.com"
+
+func newTestPlan(name string, params map[string]string) *v1alpha1.VolumeModificationPlan {
+	return &v1alpha1.VolumeModificationPlan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Generation: 1,
+		},
+		Spec: v1alpha1.VolumeModificationPlanSpec{
+			DriverName: planDriverName,
+			Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Parameters: params,
+		},
+	}
+}
+
+// newPlanClaim returns a bound PVC labelled app=db and its PV.
+func newPlanClaim(name, namespace string, annotations map[string]string) (*v1.PersistentVolumeClaim, *v1.PersistentVolume) {
+	pvc := newTestPVC(name, namespace, annotations)
+	pvc.Labels = map[string]string{"app": "db"}
+	pvc.Spec.VolumeName = name + "-pv"
+	return pvc, newTestPV(name+"-pv", name, namespace, pvc.UID, planDriverName)
+}
+
+func setupPlanController(t *testing.T, plans []runtime.Object, objects ...runtime.Object) (*planController, *fake.Clientset, *vmfake.Clientset) {
+	t.Helper()
+	k8sClient := fake.NewClientset(objects...)
+	vmClient := vmfake.NewSimpleClientset(plans...)
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+	vmFactory := externalversions.NewSharedInformerFactory(vmClient, 0)
+
+	ctrl := NewVolumeModificationPlanController(planDriverName, k8sClient, vmClient, 0, factory, vmFactory,
+		workqueue.DefaultControllerRateLimiter()).(*planController)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	if !cache.WaitForCacheSync(ctx.Done(), ctrl.HasSynced) {
+		t.Fatal("caches did not sync")
+	}
+	return ctrl, k8sClient, vmClient
+}
+
+func getPlan(t *testing.T, vmClient *vmfake.Clientset, name string) *v1alpha1.VolumeModificationPlan {
+	t.Helper()
+	plan, err := vmClient.VolumemodifierV1alpha1().VolumeModificationPlans().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func TestSyncPlan_AdmitsUpToMaxConcurrency(t *testing.T) {
+	pvcA, pvA := newPlanClaim("a", "default", nil)
+	pvcB, pvB := newPlanClaim("b", "default", nil)
+	pvcC, pvC := newPlanClaim("c", "default", nil)
+	other, otherPV := newPlanClaim("other", "default", nil)
+	other.Labels = map[string]string{"app": "web"}
+	plan := newTestPlan("to-gp3", map[string]string{"type": "gp3"})
+	plan.Spec.MaxConcurrency = 2
+
+	ctrl, k8sClient, vmClient := setupPlanController(t, []runtime.Object{plan}, pvcA, pvA, pvcB, pvB, pvcC, pvC, other, otherPV)
+	if err := ctrl.syncPlan("to-gp3"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, admitted := range map[string]bool{"a": true, "b": true, "c": false, "other": false} {
+		pvc, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := pvc.Annotations["ebs.csi.aws.com/type"] == "gp3"; got != admitted {
+			t.Errorf("PVC %s: expected admitted=%v, got annotations %v", name, admitted, pvc.Annotations)
+		}
+		if admitted && pvc.Annotations["ebs.csi.aws.com/plan"] != "to-gp3" {
+			t.Errorf("PVC %s: expected plan annotation, got %v", name, pvc.Annotations)
+		}
+	}
+
+	status := getPlan(t, vmClient, "to-gp3").Status
+	if status.Phase != v1alpha1.PlanPhaseRunning || status.InProgress != 2 || status.Pending != 1 || len(status.Claims) != 3 {
+		t.Fatalf("unexpected plan status %+v", status)
+	}
+
+	// Syncing again must not admit more PVCs while two are in progress.
+	if err := ctrl.syncPlan("to-gp3"); err != nil {
+		t.Fatal(err)
+	}
+	pvc, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "c", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pvc.Annotations["ebs.csi.aws.com/type"]; ok {
+		t.Fatalf("expected PVC c to wait for a free slot, got annotations %v", pvc.Annotations)
+	}
+}
+
+func TestSyncPlan_States(t *testing.T) {
+	failedStatus := `{"state":"failed","value":"gp3","reason":"throttled","lastTransitionTime":null}`
+
+	tests := []struct {
+		name             string
+		pvcAnnotations   map[string]string
+		pvAnnotations    map[string]string
+		owners           []runtime.Object
+		failureThreshold int32
+		paused           bool
+		expectedPhase    string
+		expectedState    string
+	}{
+		{
+			name:          "already applied",
+			pvAnnotations: map[string]string{"ebs.csi.aws.com/type": "gp3"},
+			expectedPhase: v1alpha1.PlanPhaseCompleted,
+			expectedState: v1alpha1.PlanClaimSucceeded,
+		},
+		{
+			name: "failed",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":        "gp3",
+				"ebs.csi.aws.com/type-status": failedStatus,
+			},
+			expectedPhase: v1alpha1.PlanPhaseFailed,
+			expectedState: v1alpha1.PlanClaimFailed,
+		},
+		{
+			name: "failed below threshold",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":        "gp3",
+				"ebs.csi.aws.com/type-status": failedStatus,
+			},
+			failureThreshold: 2,
+			expectedPhase:    v1alpha1.PlanPhaseCompleted,
+			expectedState:    v1alpha1.PlanClaimFailed,
+		},
+		{
+			name: "retried failure is in progress",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":        "gp3",
+				"ebs.csi.aws.com/type-status": `{"state":"failed","value":"gp3","reason":"throttled","failure":"retryable","lastTransitionTime":null}`,
+			},
+			expectedPhase: v1alpha1.PlanPhaseRunning,
+			expectedState: v1alpha1.PlanClaimInProgress,
+		},
+		{
+			name: "terminal failure",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":        "gp3",
+				"ebs.csi.aws.com/type-status": `{"state":"failed","value":"gp3","reason":"invalid type","failure":"terminal","lastTransitionTime":null}`,
+			},
+			expectedPhase: v1alpha1.PlanPhaseFailed,
+			expectedState: v1alpha1.PlanClaimFailed,
+		},
+		{
+			name: "failure of another value is ignored",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":        "gp3",
+				"ebs.csi.aws.com/type-status": `{"state":"failed","value":"io2","lastTransitionTime":null}`,
+			},
+			expectedPhase: v1alpha1.PlanPhaseRunning,
+			expectedState: v1alpha1.PlanClaimInProgress,
+		},
+		{
+			name: "owned by a VolumeModification in progress",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":                "io2",
+				"ebs.csi.aws.com/volume-modification": "to-io2",
+			},
+			owners: []runtime.Object{&v1alpha1.VolumeModification{
+				ObjectMeta: metav1.ObjectMeta{Name: "to-io2", Namespace: "default", Generation: 1},
+				Spec:       v1alpha1.VolumeModificationSpec{PersistentVolumeClaimName: "a", Parameters: map[string]string{"type": "io2"}},
+			}},
+			expectedPhase: v1alpha1.PlanPhaseRunning,
+			expectedState: v1alpha1.PlanClaimPending,
+		},
+		{
+			name: "owned by another plan in progress",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type": "io2",
+				"ebs.csi.aws.com/plan": "to-io2",
+			},
+			owners:        []runtime.Object{newTestPlan("to-io2", map[string]string{"type": "io2"})},
+			expectedPhase: v1alpha1.PlanPhaseRunning,
+			expectedState: v1alpha1.PlanClaimPending,
+		},
+		{
+			name: "owned by a finished VolumeModification",
+			pvcAnnotations: map[string]string{
+				"ebs.csi.aws.com/type":                "io2",
+				"ebs.csi.aws.com/volume-modification": "to-io2",
+			},
+			owners: []runtime.Object{&v1alpha1.VolumeModification{
+				ObjectMeta: metav1.ObjectMeta{Name: "to-io2", Namespace: "default", Generation: 1},
+				Spec:       v1alpha1.VolumeModificationSpec{PersistentVolumeClaimName: "a", Parameters: map[string]string{"type": "io2"}},
+				Status: v1alpha1.VolumeModificationStatus{
+					ObservedGeneration: 1,
+					Conditions: []metav1.Condition{{
+						Type:               v1alpha1.ConditionApplied,
+						Status:             metav1.ConditionTrue,
+						Reason:             v1alpha1.ReasonSucceeded,
+						ObservedGeneration: 1,
+					}},
+				},
+			}},
+			expectedPhase: v1alpha1.PlanPhaseRunning,
+			expectedState: v1alpha1.PlanClaimInProgress,
+		},
+		{
+			name:          "paused",
+			paused:        true,
+			expectedPhase: v1alpha1.PlanPhasePaused,
+			expectedState: v1alpha1.PlanClaimPending,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc, pv := newPlanClaim("a", "default", tc.pvcAnnotations)
+			for key, value := range tc.pvAnnotations {
+				pv.Annotations[key] = value
+			}
+			plan := newTestPlan("to-gp3", map[string]string{"type": "gp3"})
+			plan.Spec.FailureThreshold = tc.failureThreshold
+			plan.Spec.Paused = tc.paused
+
+			ctrl, k8sClient, vmClient := setupPlanController(t, append([]runtime.Object{plan}, tc.owners...), pvc, pv)
+			if err := ctrl.syncPlan("to-gp3"); err != nil {
+				t.Fatal(err)
+			}
+			if tc.expectedState == v1alpha1.PlanClaimPending {
+				claim, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "a", metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if claim.Annotations["ebs.csi.aws.com/plan"] == "to-gp3" {
+					t.Errorf("expected the PVC not to be admitted, got annotations %v", claim.Annotations)
+				}
+			}
+
+			status := getPlan(t, vmClient, "to-gp3").Status
+			if status.Phase != tc.expectedPhase {
+				t.Errorf("expected phase %s, got %s", tc.expectedPhase, status.Phase)
+			}
+			if len(status.Claims) != 1 || status.Claims[0].State != tc.expectedState {
+				t.Errorf("expected claim state %s, got %+v", tc.expectedState, status.Claims)
+			}
+		})
+	}
+}
+
+func TestSyncPlan_NamespaceSelector(t *testing.T) {
+	prod := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}}
+	dev := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}}
+	prodPVC, prodPV := newPlanClaim("a", "prod", nil)
+	devPVC, devPV := newPlanClaim("b", "dev", nil)
+	plan := newTestPlan("prod-to-gp3", map[string]string{"type": "gp3"})
+	plan.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	plan.Spec.MaxConcurrency = 10
+
+	ctrl, _, vmClient := setupPlanController(t, []runtime.Object{plan}, prod, dev, prodPVC, prodPV, devPVC, devPV)
+	if err := ctrl.syncPlan("prod-to-gp3"); err != nil {
+		t.Fatal(err)
+	}
+
+	status := getPlan(t, vmClient, "prod-to-gp3").Status
+	if len(status.Claims) != 1 || status.Claims[0].Namespace != "prod" {
+		t.Fatalf("expected only the PVC in the prod namespace to be selected, got %+v", status.Claims)
+	}
+}
+
+func TestSyncPlan_OtherDriverIgnored(t *testing.T) {
+	pvc, pv := newPlanClaim("a", "default", nil)
+	plan := newTestPlan("other", map[string]string{"type": "gp3"})
+	plan.Spec.DriverName = "other.csi.example.com"
+
+	ctrl, k8sClient, vmClient := setupPlanController(t, []runtime.Object{plan}, pvc, pv)
+	if err := ctrl.syncPlan("other"); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := getPlan(t, vmClient, "other").Status; status.Phase != "" {
+		t.Fatalf("expected the plan of another driver to be ignored, got %+v", status)
+	}
+	updated, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updated.Annotations["ebs.csi.aws.com/type"]; ok {
+		t.Fatalf("expected PVC not to be modified, got annotations %v", updated.Annotations)
+	}
+}
+
+func TestSyncPlan_AdmitsNextClaimOnceModified(t *testing.T) {
+	pvcA, pvA := newPlanClaim("a", "default", nil)
+	pvcB, pvB := newPlanClaim("b", "default", nil)
+	plan := newTestPlan("to-gp3", map[string]string{"type": "gp3"})
+
+	ctrl, k8sClient, vmClient := setupPlanController(t, []runtime.Object{plan}, pvcA, pvA, pvcB, pvB)
+
+	for i, name := range []string{"a", "b"} {
+		if err := ctrl.syncPlan("to-gp3"); err != nil {
+			t.Fatal(err)
+		}
+		status := getPlan(t, vmClient, "to-gp3").Status
+		if status.InProgress != 1 || status.Succeeded != int32(i) || status.Claims[i].State != v1alpha1.PlanClaimInProgress {
+			t.Fatalf("expected PVC %s to be in progress, got %+v", name, status)
+		}
+
+		// Record the modification on the PV as the ModifyController does.
+		pv, err := k8sClient.CoreV1().PersistentVolumes().Get(context.TODO(), name+"-pv", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pv.Annotations["ebs.csi.aws.com/type"] = "gp3"
+		if _, err := k8sClient.CoreV1().PersistentVolumes().Update(context.TODO(), pv, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		waitForCachedPV(t, ctrl.volumes, name+"-pv", "ebs.csi.aws.com/type", "gp3")
+	}
+
+	if err := ctrl.syncPlan("to-gp3"); err != nil {
+		t.Fatal(err)
+	}
+	status := getPlan(t, vmClient, "to-gp3").Status
+	if status.Phase != v1alpha1.PlanPhaseCompleted || status.Succeeded != 2 {
+		t.Fatalf("expected plan to complete, got %+v", status)
+	}
+
+	// A completed plan is not synced again until its spec changes.
+	if _, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Create(context.TODO(), func() *v1.PersistentVolumeClaim {
+		pvc, _ := newPlanClaim("c", "default", nil)
+		return pvc
+	}(), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctrl.syncPlan("to-gp3"); err != nil {
+		t.Fatal(err)
+	}
+	if got := getPlan(t, vmClient, "to-gp3").Status; len(got.Claims) != 2 {
+		t.Fatalf("expected completed plan to be left untouched, got %+v", got)
+	}
+}
+
+func waitForCachedPV(t *testing.T, store cache.Store, name, annotation, value string) {
+	t.Helper()
+	deadline := time.After(3 * time.Second)
+	for {
+		if obj, exists, _ := store.GetByKey(name); exists && obj.(*v1.PersistentVolume).Annotations[annotation] == value {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for PV %s to be cached with %s=%s", name, annotation, value)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestEnqueueForClaims_OnlyMatchingPlans(t *testing.T) {
+	db := newTestPlan("db", map[string]string{"type": "gp3"})
+	web := newTestPlan("web", map[string]string{"type": "gp3"})
+	web.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	prodOnly := newTestPlan("prod-only", map[string]string{"type": "gp3"})
+	prodOnly.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+	other := newTestPlan("other", map[string]string{"type": "gp3"})
+	other.Spec.DriverName = "other.csi.example.com"
+
+	plans := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, plan := range []*v1alpha1.VolumeModificationPlan{db, web, prodOnly, other} {
+		if err := plans.Add(plan); err != nil {
+			t.Fatal(err)
+		}
+	}
+	namespaces := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := namespaces.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		claims   []interface{}
+		expected []string
+	}{
+		{
+			name:     "matching labels",
+			claims:   []interface{}{newLabelledClaim(map[string]string{"app": "db"})},
+			expected: []string{"db"},
+		},
+		{
+			name:     "no matching plan",
+			claims:   []interface{}{newLabelledClaim(map[string]string{"app": "cache"})},
+			expected: nil,
+		},
+		{
+			name: "labels changed",
+			claims: []interface{}{
+				newLabelledClaim(map[string]string{"app": "web"}),
+				newLabelledClaim(map[string]string{"app": "db"}),
+			},
+			expected: []string{"db", "web"},
+		},
+		{
+			name:     "deleted",
+			claims:   []interface{}{cache.DeletedFinalStateUnknown{Key: "default/a", Obj: newLabelledClaim(map[string]string{"app": "web"})}},
+			expected: []string{"web"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := &planController{
+				name:       planDriverName,
+				queue:      workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+				lister:     listers.NewVolumeModificationPlanLister(plans),
+				namespaces: corelisters.NewNamespaceLister(namespaces),
+			}
+			defer ctrl.queue.ShutDown()
+
+			ctrl.enqueueForClaims(tc.claims...)
+
+			var queued []string
+			for ctrl.queue.Len() > 0 {
+				key, _ := ctrl.queue.Get()
+				queued = append(queued, key.(string))
+				ctrl.queue.Done(key)
+			}
+			sort.Strings(queued)
+			if !reflect.DeepEqual(queued, tc.expected) {
+				t.Fatalf("expected plans %v to be queued, got %v", tc.expected, queued)
+			}
+		})
+	}
+}
+
+func newLabelledClaim(labels map[string]string) *v1.PersistentVolumeClaim {
+	pvc := newTestPVC("a", "default", nil)
+	pvc.Labels = labels
+	return pvc
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/klog/v2"
+)
+
+// parameterPolicy bounds the values a modification parameter can be set to.
+// Min and Max apply to integer values.
+type parameterPolicy struct {
+	Min           *int64   `json:"min,omitempty"`
+	Max           *int64   `json:"max,omitempty"`
+	AllowedValues []string `json:"allowedValues,omitempty"`
+}
+
+// modificationPolicy is the policy set on a StorageClass through the
+// AnnotationModificationPolicyPattern annotation, keyed by parameter.
+// Parameters missing from the policy cannot be modified, e.g.
+//
+//	{"type": {"allowedValues": ["gp3"]}, "iops": {"min": 3000, "max": 16000}}
+type modificationPolicy map[string]parameterPolicy
+
+func parseModificationPolicy(value string) (modificationPolicy, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	var policy modificationPolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// check reports every parameter violating the policy in a single error.
+func (p modificationPolicy) check(params map[string]string) error {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var violations []string
+	for _, key := range keys {
+		bounds, ok := p[key]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("parameter %q is not allowed", key))
+			continue
+		}
+		if err := bounds.check(params[key]); err != nil {
+			violations = append(violations, fmt.Sprintf("parameter %q: %v", key, err))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("%s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func (p parameterPolicy) check(value string) error {
+	if p.Min != nil || p.Max != nil {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("value %q is not an integer", value)
+		}
+		if p.Min != nil && n < *p.Min {
+			return fmt.Errorf("value %d is below the minimum of %d", n, *p.Min)
+		}
+		if p.Max != nil && n > *p.Max {
+			return fmt.Errorf("value %d is above the maximum of %d", n, *p.Max)
+		}
+	}
+	if len(p.AllowedValues) > 0 && !slices.Contains(p.AllowedValues, value) {
+		return fmt.Errorf("value %q is not one of %s", value, strings.Join(p.AllowedValues, ", "))
+	}
+	return nil
+}
+
+// checkModificationPolicy checks params against the modification policy of
+// the StorageClass of the volume, if any. A policy that cannot be parsed
+// refuses every modification.
+func (c *modifyController) checkModificationPolicy(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string) error {
+	className := storageClassName(pv, pvc)
+	if className == "" || c.classes == nil {
+		return nil
+	}
+
+	obj, exists, err := c.classes.GetByKey(className)
+	if err != nil || !exists {
+		// The StorageClass may have been deleted after provisioning.
+		klog.V(4).InfoS("StorageClass not found, skipping modification policy", "pvc", util.PVCKey(pvc), "storageClass", className, "err", err)
+		return nil
+	}
+	class, ok := obj.(*storagev1.StorageClass)
+	if !ok {
+		return nil
+	}
+
+	annotation := fmt.Sprintf(AnnotationModificationPolicyPattern, c.name)
+	value, ok := class.Annotations[annotation]
+	if !ok {
+		return nil
+	}
+	policy, err := parseModificationPolicy(value)
+	if err != nil {
+		return fmt.Errorf("invalid policy %s of StorageClass %s: %v", annotation, className, err)
+	}
+	if err := policy.check(params); err != nil {
+		return fmt.Errorf("modification violates policy %s of StorageClass %s: %v", annotation, className, err)
+	}
+	return nil
+}
+
+// updateStorageClass requeues the PVCs of a StorageClass whose modification
+// policy changed, so that modifications it refused are attempted again.
+func (c *modifyController) updateStorageClass(old, new interface{}) {
+	oldClass, ok := old.(*storagev1.StorageClass)
+	if !ok {
+		return
+	}
+	newClass, ok := new.(*storagev1.StorageClass)
+	if !ok {
+		return
+	}
+	annotation := fmt.Sprintf(AnnotationModificationPolicyPattern, c.name)
+	if oldClass.Annotations[annotation] == newClass.Annotations[annotation] {
+		return
+	}
+
+	klog.InfoS("Modification policy of StorageClass changed, requeueing its PVCs", "storageClass", newClass.Name)
+	for _, obj := range c.claims.List() {
+		pvc, ok := obj.(*v1.PersistentVolumeClaim)
+		if ok && pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == newClass.Name {
+			c.addPVC(pvc)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestModificationPolicyCheck(t *testing.T) {
+	policy, err := parseModificationPolicy(`{"type": {"allowedValues": ["gp3", "io2"]}, "iops": {"min": 3000, "max": 16000}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		params      map[string]string
+		expectedErr string
+	}{
+		{
+			name:   "within bounds",
+			params: map[string]string{"type": "gp3", "iops": "16000"},
+		},
+		{
+			name:        "above maximum",
+			params:      map[string]string{"iops": "64000"},
+			expectedErr: `parameter "iops": value 64000 is above the maximum of 16000`,
+		},
+		{
+			name:        "below minimum",
+			params:      map[string]string{"iops": "100"},
+			expectedErr: `parameter "iops": value 100 is below the minimum of 3000`,
+		},
+		{
+			name:        "not an integer",
+			params:      map[string]string{"iops": "lots"},
+			expectedErr: `parameter "iops": value "lots" is not an integer`,
+		},
+		{
+			name:        "value not allowed",
+			params:      map[string]string{"type": "io1"},
+			expectedErr: `parameter "type": value "io1" is not one of gp3, io2`,
+		},
+		{
+			name:        "parameter not allowed",
+			params:      map[string]string{"throughput": "500", "iops": "64000"},
+			expectedErr: `parameter "iops": value 64000 is above the maximum of 16000; parameter "throughput" is not allowed`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.check(tc.params)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseModificationPolicy_Invalid(t *testing.T) {
+	for _, value := range []string{`not json`, `{"iops": {"maximum": 16000}}`, `{"iops": {"max": "16000"}}`} {
+		if _, err := parseModificationPolicy(value); err == nil {
+			t.Errorf("expected policy %q to be invalid", value)
+		}
+	}
+}
+
+func newTestStorageClass(name, driverName, policy string) *storagev1.StorageClass {
+	class := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		Provisioner: driverName,
+	}
+	if policy != "" {
+		class.Annotations = map[string]string{driverName + "/modification-policy": policy}
+	}
+	return class
+}
+
+func TestModifyPVC_PolicyViolationRefused(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("policy-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/type": "io2",
+		"ebs.csi.aws.com/iops": "64000",
+	})
+	pv := newTestPV("testPV", "policy-pvc", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+	class := newTestStorageClass("gp3", driverName, `{"type": {"allowedValues": ["gp3"]}, "iops": {"max": 16000}}`)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv, class)
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationFailed, 3*time.Second)
+	if !strings.Contains(event.Message, "policy ebs.csi.aws.com/modification-policy of StorageClass gp3") {
+		t.Fatalf("expected event to name the violated policy, got %q", event.Message)
+	}
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "policy-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if !strings.Contains(status.Reason, `value 64000 is above the maximum of 16000`) ||
+		!strings.Contains(status.Reason, `value "io2" is not one of gp3`) {
+		t.Fatalf("unexpected failure reason %q", status.Reason)
+	}
+
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected policy violation not to reach the driver, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestModifyPVC_InvalidPolicyRefused(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("policy-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "policy-pvc", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+	class := newTestStorageClass("gp3", driverName, `{"iops": {"maximum": 16000}}`)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv, class)
+
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "policy-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if !strings.Contains(status.Reason, "invalid policy ebs.csi.aws.com/modification-policy of StorageClass gp3") {
+		t.Fatalf("unexpected failure reason %q", status.Reason)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call with an invalid policy, got %d", client.GetModifyCallCount())
+	}
+}
+
+func TestModifyPVC_WithinPolicy(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("policy-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "policy-pvc", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+	class := newTestStorageClass("gp3", driverName, `{"iops": {"min": 3000, "max": 16000}}`)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	setupControllerWithClient(t, driverName, client, nil, pvc, pv, class)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+}
+
+func TestModifyPVC_RetriedWhenPolicyChanges(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	className := "gp3"
+	pvc := newTestPVC("policy-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "20000"})
+	pvc.Spec.StorageClassName = &className
+	pv := newTestPV("testPV", "policy-pvc", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = className
+	class := newTestStorageClass(className, driverName, `{"iops": {"max": 16000}}`)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv, class)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "policy-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected policy violation not to reach the driver, got %d modify calls", client.GetModifyCallCount())
+	}
+
+	class = class.DeepCopy()
+	class.Annotations[driverName+"/modification-policy"] = `{"iops": {"max": 32000}}`
+	if _, err := ctrl.kubeClient.StorageV1().StorageClasses().Update(context.TODO(), class, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForModifyCount(t, client, 1, 3*time.Second)
+}
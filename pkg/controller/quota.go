@@ -0,0 +1,311 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// quotaUsage sums the parameters limited by hard over the volumes of the
+// driver bound to PVCs of the namespace. The value of a parameter is the one
+// returned by requested for the volume, if any, or else the one last applied
+// as recorded in the "<driver-name>/<key>" annotations of the PV. Volumes
+// whose parameter was never modified are not counted.
+func quotaUsage(driverName, namespace string, hard map[string]int64, volumes []interface{}, requested func(*v1.PersistentVolume) map[string]string) map[string]int64 {
+	used := make(map[string]int64, len(hard))
+	for key := range hard {
+		used[key] = 0
+	}
+	for _, obj := range volumes {
+		pv, ok := obj.(*v1.PersistentVolume)
+		if !ok || pv.Spec.ClaimRef == nil || pv.Spec.ClaimRef.Namespace != namespace || !ownsVolume(driverName, pv) {
+			continue
+		}
+		var params map[string]string
+		if requested != nil {
+			params = requested(pv)
+		}
+		for key := range hard {
+			value, ok := params[key]
+			if !ok {
+				value, ok = pv.Annotations[fmt.Sprintf("%s/%s", driverName, key)]
+			}
+			if !ok {
+				continue
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			used[key] += n
+		}
+	}
+	return used
+}
+
+// checkQuota refuses the modification if it would raise the usage of a
+// VolumeModificationQuota of the namespace above its hard limit. Otherwise
+// the parameters are reserved until the returned function is called, so that
+// concurrent modifications in the namespace are counted.
+func (c *modifyController) checkQuota(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, params map[string]string) (func(), error) {
+	release := func() {}
+	if c.quotas == nil {
+		return release, nil
+	}
+	quotas, err := c.quotas.VolumeModificationQuotas(pvc.Namespace).List(labels.Everything())
+	if err != nil {
+		return release, fmt.Errorf("cannot list VolumeModificationQuotas of namespace %s: %w", pvc.Namespace, err)
+	}
+
+	c.quotaMu.Lock()
+	defer c.quotaMu.Unlock()
+
+	volumes := c.volumes.List()
+	var violations []string
+	for _, quota := range quotas {
+		if quota.Spec.DriverName != c.name {
+			continue
+		}
+		before := quotaUsage(c.name, pvc.Namespace, quota.Spec.Hard, volumes, c.requestedParams)
+		after := quotaUsage(c.name, pvc.Namespace, quota.Spec.Hard, volumes, func(volume *v1.PersistentVolume) map[string]string {
+			if volume.Name == pv.Name {
+				return params
+			}
+			return c.requestedParams(volume)
+		})
+		for _, key := range sortedKeys(quota.Spec.Hard) {
+			// Decreases are always allowed, even above a lowered limit.
+			if hard := quota.Spec.Hard[key]; after[key] > hard && after[key] > before[key] {
+				violations = append(violations, fmt.Sprintf("%s of VolumeModificationQuota %s would use %d of %d", key, quota.Name, after[key], hard))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return release, fmt.Errorf("modification exceeds quota: %s", strings.Join(violations, "; "))
+	}
+
+	c.quotaReservations[pv.Name] = params
+	return func() {
+		c.quotaMu.Lock()
+		defer c.quotaMu.Unlock()
+		delete(c.quotaReservations, pv.Name)
+	}, nil
+}
+
+// requestedParams returns the parameters of a modification of the volume
+// that is in progress and not recorded on the PV yet. c.quotaMu must be held.
+func (c *modifyController) requestedParams(pv *v1.PersistentVolume) map[string]string {
+	if params, ok := c.quotaReservations[pv.Name]; ok {
+		return params
+	}
+	if pv.Spec.ClaimRef == nil {
+		return nil
+	}
+	if op := c.getOperation(pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name); op != nil {
+		return op.params
+	}
+	return nil
+}
+
+// updateQuota requeues the PVCs of the namespace when the limits of a
+// VolumeModificationQuota change, so that modifications it refused are
+// attempted again.
+func (c *modifyController) updateQuota(old, new interface{}) {
+	oldQuota, ok := old.(*v1alpha1.VolumeModificationQuota)
+	if !ok {
+		return
+	}
+	newQuota, ok := new.(*v1alpha1.VolumeModificationQuota)
+	if !ok || newQuota.Spec.DriverName != c.name || equality.Semantic.DeepEqual(oldQuota.Spec, newQuota.Spec) {
+		return
+	}
+
+	klog.InfoS("VolumeModificationQuota changed, requeueing PVCs of its namespace", "quota", newQuota.Namespace+"/"+newQuota.Name)
+	for _, obj := range c.claims.List() {
+		pvc, ok := obj.(*v1.PersistentVolumeClaim)
+		if ok && pvc.Namespace == newQuota.Namespace {
+			c.addPVC(pvc)
+		}
+	}
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewVolumeModificationQuotaController returns a controller that reports the
+// usage of VolumeModificationQuota objects in their status. The quotas are
+// enforced by the ModifyController, see WithModificationQuotas.
+func NewVolumeModificationQuotaController(
+	name string,
+	client versioned.Interface,
+	resyncPeriod time.Duration,
+	informerFactory informers.SharedInformerFactory,
+	vmInformerFactory externalversions.SharedInformerFactory,
+	rateLimiter workqueue.RateLimiter,
+) ModifyController {
+	pvInformer := informerFactory.Core().V1().PersistentVolumes()
+	quotaInformer := vmInformerFactory.Volumemodifier().V1alpha1().VolumeModificationQuotas()
+	queue := workqueue.NewNamedRateLimitingQueue(rateLimiter, fmt.Sprintf("%s-volume-modification-quota", name))
+
+	ctrl := &quotaController{
+		name:        name,
+		client:      client,
+		queue:       queue,
+		lister:      quotaInformer.Lister(),
+		quotaSynced: quotaInformer.Informer().HasSynced,
+		pvSynced:    pvInformer.Informer().HasSynced,
+		volumes:     pvInformer.Informer().GetStore(),
+	}
+
+	ctrl.handlers.addWithResyncPeriod(quotaInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.enqueue,
+		UpdateFunc: func(_, new interface{}) { ctrl.enqueue(new) },
+	}, resyncPeriod)
+	// The usage of a quota changes with the annotations of the PVs bound
+	// to PVCs of its namespace.
+	ctrl.handlers.add(pvInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.enqueueForVolume,
+		UpdateFunc: func(_, new interface{}) { ctrl.enqueueForVolume(new) },
+		DeleteFunc: ctrl.enqueueForVolume,
+	})
+	informerFactory.Start(wait.NeverStop)
+	vmInformerFactory.Start(wait.NeverStop)
+
+	return ctrl
+}
+
+type quotaController struct {
+	name        string
+	client      versioned.Interface
+	queue       workqueue.RateLimitingInterface
+	lister      listers.VolumeModificationQuotaLister
+	quotaSynced cache.InformerSynced
+	pvSynced    cache.InformerSynced
+	handlers    eventHandlers
+
+	volumes cache.Store
+}
+
+func (c *quotaController) Run(workers int, ctx context.Context) {
+	defer c.queue.ShutDown()
+	defer c.handlers.removeAll()
+
+	klog.InfoS("Starting VolumeModificationQuota controller", "name", c.name)
+	defer klog.InfoS("Shutting down VolumeModificationQuota controller", "name", c.name)
+
+	stopCh := ctx.Done()
+	if !cache.WaitForCacheSync(stopCh, c.quotaSynced, c.pvSynced) {
+		klog.Errorf("Cannot sync VolumeModificationQuota or pv caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.syncQuotas, 0, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (c *quotaController) HasSynced() bool {
+	return c.quotaSynced() && c.pvSynced()
+}
+
+func (c *quotaController) enqueue(obj interface{}) {
+	key, err := getObjectKeys(obj)
+	if err != nil {
+		klog.ErrorS(err, "unable to add obj to VolumeModificationQuota queue")
+		return
+	}
+	c.queue.Add(key)
+}
+
+func (c *quotaController) enqueueForVolume(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pv, ok := obj.(*v1.PersistentVolume)
+	if !ok || pv.Spec.ClaimRef == nil || !ownsVolume(c.name, pv) {
+		return
+	}
+	quotas, err := c.lister.VolumeModificationQuotas(pv.Spec.ClaimRef.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "cannot list VolumeModificationQuotas", "namespace", pv.Spec.ClaimRef.Namespace)
+		return
+	}
+	for _, quota := range quotas {
+		if quota.Spec.DriverName == c.name {
+			c.enqueue(quota)
+		}
+	}
+}
+
+func (c *quotaController) syncQuotas() {
+	key, quit := c.queue.Get()
+	if quit {
+		return
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncQuota(key.(string)); err != nil {
+		klog.ErrorS(err, "error syncing VolumeModificationQuota", "key", key)
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+}
+
+func (c *quotaController) syncQuota(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return fmt.Errorf("cannot get namespace and name from key (%s): %w", key, err)
+	}
+	quota, err := c.lister.VolumeModificationQuotas(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		klog.InfoS("VolumeModificationQuota is deleted or does not exist", "key", key)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get VolumeModificationQuota %s: %w", key, err)
+	}
+	if quota.Spec.DriverName != c.name {
+		return nil
+	}
+
+	status := v1alpha1.VolumeModificationQuotaStatus{
+		ObservedGeneration: quota.Generation,
+		Used:               quotaUsage(c.name, namespace, quota.Spec.Hard, c.volumes.List(), nil),
+	}
+	if equality.Semantic.DeepEqual(quota.Status, status) {
+		return nil
+	}
+	newQuota := quota.DeepCopy()
+	newQuota.Status = status
+	if _, err := c.client.VolumemodifierV1alpha1().VolumeModificationQuotas(namespace).UpdateStatus(context.TODO(), newQuota, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("cannot update status of VolumeModificationQuota %s: %w", key, err)
+	}
+	return nil
+}
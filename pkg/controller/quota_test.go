@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	vmfake "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/fake"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func newTestQuota(name, driverName string, hard map[string]int64) *v1alpha1.VolumeModificationQuota {
+	return &v1alpha1.VolumeModificationQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha1.VolumeModificationQuotaSpec{
+			DriverName: driverName,
+			Hard:       hard,
+		},
+	}
+}
+
+func newQuotaTestPV(name, pvcNamespace, driverName, iops string) *v1.PersistentVolume {
+	pv := newTestPV(name, "claim-"+name, pvcNamespace, types.UID("uid-"+name), driverName)
+	if iops != "" {
+		pv.Annotations[driverName+"/iops"] = iops
+	}
+	return pv
+}
+
+func TestQuotaUsage(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	volumes := []interface{}{
+		newQuotaTestPV("pv-1", "default", driverName, "3000"),
+		newQuotaTestPV("pv-2", "default", driverName, "5000"),
+		newQuotaTestPV("pv-3", "default", driverName, ""),
+		newQuotaTestPV("pv-4", "default", driverName, "lots"),
+		newQuotaTestPV("pv-5", "other", driverName, "16000"),
+		newQuotaTestPV("pv-6", "default", "other.csi.example.com", "16000"),
+		newTestPV("pv-7", "", "", "", driverName),
+	}
+	// Annotations with the prefix of this driver are ignored on volumes of other drivers.
+	volumes[5].(*v1.PersistentVolume).Annotations[driverName+"/iops"] = "16000"
+	hard := map[string]int64{"iops": 100000, "throughput": 1000}
+
+	used := quotaUsage(driverName, "default", hard, volumes, nil)
+	if used["iops"] != 8000 || used["throughput"] != 0 || len(used) != 2 {
+		t.Fatalf("unexpected usage %v", used)
+	}
+
+	used = quotaUsage(driverName, "default", hard, volumes, func(pv *v1.PersistentVolume) map[string]string {
+		if pv.Name == "pv-3" {
+			return map[string]string{"iops": "4000", "throughput": "250"}
+		}
+		return nil
+	})
+	if used["iops"] != 12000 || used["throughput"] != 250 {
+		t.Fatalf("unexpected usage with requested parameters %v", used)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	tests := []struct {
+		name         string
+		quotas       []*v1alpha1.VolumeModificationQuota
+		reservations map[string]map[string]string
+		params       map[string]string
+		expectedErr  string
+	}{
+		{
+			name:   "no quota",
+			params: map[string]string{"iops": "64000"},
+		},
+		{
+			name:   "within quota",
+			quotas: []*v1alpha1.VolumeModificationQuota{newTestQuota("budget", driverName, map[string]int64{"iops": 20000})},
+			params: map[string]string{"iops": "12000"},
+		},
+		{
+			name:        "exceeds quota",
+			quotas:      []*v1alpha1.VolumeModificationQuota{newTestQuota("budget", driverName, map[string]int64{"iops": 20000})},
+			params:      map[string]string{"iops": "18000"},
+			expectedErr: "iops of VolumeModificationQuota budget would use 21000 of 20000",
+		},
+		{
+			name:   "decrease above lowered limit",
+			quotas: []*v1alpha1.VolumeModificationQuota{newTestQuota("budget", driverName, map[string]int64{"iops": 5000})},
+			params: map[string]string{"iops": "4000"},
+		},
+		{
+			name:   "unlimited parameter",
+			quotas: []*v1alpha1.VolumeModificationQuota{newTestQuota("budget", driverName, map[string]int64{"iops": 20000})},
+			params: map[string]string{"throughput": "1000"},
+		},
+		{
+			name:   "quota of another driver",
+			quotas: []*v1alpha1.VolumeModificationQuota{newTestQuota("budget", "other.csi.example.com", map[string]int64{"iops": 1})},
+			params: map[string]string{"iops": "16000"},
+		},
+		{
+			name:         "in-flight modification counted",
+			quotas:       []*v1alpha1.VolumeModificationQuota{newTestQuota("budget", driverName, map[string]int64{"iops": 20000})},
+			reservations: map[string]map[string]string{"pv-other": {"iops": "10000"}},
+			params:       map[string]string{"iops": "12000"},
+			expectedErr:  "iops of VolumeModificationQuota budget would use 22000 of 20000",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := newTestController(driverName)
+			ctrl.volumes = cache.NewStore(cache.MetaNamespaceKeyFunc)
+			ctrl.volumes.Add(newQuotaTestPV("pv", "default", driverName, "8000"))
+			ctrl.volumes.Add(newQuotaTestPV("pv-other", "default", driverName, "3000"))
+			ctrl.quotaReservations = make(map[string]map[string]string)
+			for name, params := range tc.reservations {
+				ctrl.quotaReservations[name] = params
+			}
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, quota := range tc.quotas {
+				indexer.Add(quota)
+			}
+			ctrl.quotas = listers.NewVolumeModificationQuotaLister(indexer)
+
+			pv, _, _ := ctrl.volumes.GetByKey("pv")
+			pvc := newTestPVC("claim-pv", "default", nil)
+			release, err := ctrl.checkQuota(pv.(*v1.PersistentVolume), pvc, tc.params)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(tc.quotas) > 0 && ctrl.quotaReservations["pv"] == nil {
+				t.Fatal("expected parameters to be reserved")
+			}
+			release()
+			if _, ok := ctrl.quotaReservations["pv"]; ok {
+				t.Fatal("expected reservation to be released")
+			}
+		})
+	}
+}
+
+// setupQuotaControllers runs a ModifyController enforcing quotas and the
+// VolumeModificationQuota controller.
+func setupQuotaControllers(t *testing.T, driverName string, client *csi.FakeClient, quotas []runtime.Object, objects ...runtime.Object) (*modifyController, versioned.Interface) {
+	t.Helper()
+	k8sClient := fake.NewClientset(objects...)
+	vmClient := vmfake.NewSimpleClientset(quotas...)
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+	vmFactory := externalversions.NewSharedInformerFactory(vmClient, 0)
+
+	mod, err := modifier.NewFromClient(driverName, client, k8sClient, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := NewModifyController(driverName, mod, k8sClient, 0, factory,
+		workqueue.DefaultControllerRateLimiter(), false, WithModificationQuotas(vmFactory))
+	qc := NewVolumeModificationQuotaController(driverName, vmClient, 0, factory, vmFactory,
+		workqueue.DefaultControllerRateLimiter())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	go NewGroup(mc, qc).Run(1, ctx)
+	t.Cleanup(cancel)
+	return mc.(*modifyController), vmClient
+}
+
+func TestModifyPVC_QuotaExceededRefused(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("quota-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "50000"})
+	pv := newTestPV("testPV", "quota-pvc", "default", "test-uid", driverName)
+	other := newQuotaTestPV("pv-other", "default", driverName, "60000")
+	quota := newTestQuota("budget", driverName, map[string]int64{"iops": 100000})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl, _ := setupQuotaControllers(t, driverName, client, []runtime.Object{quota}, pvc, pv, other)
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationFailed, 3*time.Second)
+	if !strings.Contains(event.Message, "iops of VolumeModificationQuota budget would use 110000 of 100000") {
+		t.Fatalf("expected event to name the exceeded quota, got %q", event.Message)
+	}
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "quota-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected modification exceeding the quota not to reach the driver, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestModifyPVC_WithinQuota(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("quota-pvc", "default", map[string]string{"ebs.csi.aws.com/iops": "30000"})
+	pv := newTestPV("testPV", "quota-pvc", "default", "test-uid", driverName)
+	other := newQuotaTestPV("pv-other", "default", driverName, "60000")
+	quota := newTestQuota("budget", driverName, map[string]int64{"iops": 100000})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	setupQuotaControllers(t, driverName, client, []runtime.Object{quota}, pvc, pv, other)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+}
+
+func TestQuotaController_ReportsUsage(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	quota := newTestQuota("budget", driverName, map[string]int64{"iops": 100000, "throughput": 2000})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	_, vmClient := setupQuotaControllers(t, driverName, client, []runtime.Object{quota},
+		newQuotaTestPV("pv-1", "default", driverName, "60000"),
+		newQuotaTestPV("pv-2", "default", driverName, "3000"),
+		newQuotaTestPV("pv-3", "other", driverName, "16000"))
+
+	deadline := time.After(3 * time.Second)
+	for {
+		got, err := vmClient.VolumemodifierV1alpha1().VolumeModificationQuotas("default").Get(context.TODO(), "budget", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status.Used["iops"] == 63000 {
+			if got.Status.Used["throughput"] != 0 {
+				t.Fatalf("unexpected throughput usage %v", got.Status.Used)
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for quota usage, got %v", got.Status.Used)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Keys of the context passed to the driver with every modification. The
+// csi.storage.k8s.io keys are the ones external-provisioner passes to
+// CreateVolume with --extra-create-metadata.
+const (
+	ContextKeyPVCName      = "csi.storage.k8s.io/pvc/name"
+	ContextKeyPVCNamespace = "csi.storage.k8s.io/pvc/namespace"
+	ContextKeyPVName       = "csi.storage.k8s.io/pv/name"
+
+	ContextKeyPVCUID           = "volumemodifier.k8s.aws/pvc/uid"
+	ContextKeyStorageClassName = "volumemodifier.k8s.aws/storageclass/name"
+	// ContextKeyRequestedBy is RequestedByAnnotation or the kind and name
+	// of the object that requested the modification, e.g.
+	// "VolumeModificationPlan/gp3-migration".
+	ContextKeyRequestedBy = "volumemodifier.k8s.aws/requested-by"
+
+	// ContextKeyPVCLabelPrefix and ContextKeyPVCAnnotationPrefix prefix the
+	// labels and annotations of the PVC passed through to the driver, see
+	// WithRequestContextPassThrough.
+	ContextKeyPVCLabelPrefix      = "volumemodifier.k8s.aws/pvc/label/"
+	ContextKeyPVCAnnotationPrefix = "volumemodifier.k8s.aws/pvc/annotation/"
+
+	// RequestedByAnnotation is used for modifications requested through
+	// the "<driver-name>/<key>" annotations of the PVC.
+	RequestedByAnnotation = "annotation"
+)
+
+// requestContext returns the context of the modification of the PV requested
+// for the PVC, including the given labels and annotations of the PVC when
+// they are set.
+func requestContext(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, requestedBy string, labelKeys, annotationKeys []string) map[string]string {
+	reqContext := map[string]string{
+		ContextKeyPVCName:      pvc.Name,
+		ContextKeyPVCNamespace: pvc.Namespace,
+		ContextKeyPVCUID:       string(pvc.UID),
+		ContextKeyPVName:       pv.Name,
+		ContextKeyRequestedBy:  requestedBy,
+	}
+	if className := storageClassName(pv, pvc); className != "" {
+		reqContext[ContextKeyStorageClassName] = className
+	}
+	for _, key := range labelKeys {
+		if value, ok := pvc.Labels[key]; ok {
+			reqContext[ContextKeyPVCLabelPrefix+key] = value
+		}
+	}
+	for _, key := range annotationKeys {
+		if value, ok := pvc.Annotations[key]; ok {
+			reqContext[ContextKeyPVCAnnotationPrefix+key] = value
+		}
+	}
+	return reqContext
+}
+
+// storageClassName returns the StorageClass of the volume, falling back to
+// the one requested by the PVC.
+func storageClassName(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) string {
+	if pv.Spec.StorageClassName != "" {
+		return pv.Spec.StorageClassName
+	}
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return ""
+}
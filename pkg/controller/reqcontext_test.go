@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+)
+
+func TestRequestContext(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	className := "gp3"
+	pvc := newTestPVC("data", "default", map[string]string{"owner": "storage-team", "ebs.csi.aws.com/iops": "5000"})
+	pvc.Labels = map[string]string{"app.kubernetes.io/name": "db", "tier": "backend"}
+	pvc.Spec.StorageClassName = &className
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+
+	got := requestContext(pv, pvc, RequestedByAnnotation, []string{"app.kubernetes.io/name", "missing"}, []string{"owner"})
+	expected := map[string]string{
+		ContextKeyPVCName:          "data",
+		ContextKeyPVCNamespace:     "default",
+		ContextKeyPVCUID:           "test-uid",
+		ContextKeyPVName:           "testPV",
+		ContextKeyRequestedBy:      RequestedByAnnotation,
+		ContextKeyStorageClassName: "gp3",
+		"volumemodifier.k8s.aws/pvc/label/app.kubernetes.io/name": "db",
+		"volumemodifier.k8s.aws/pvc/annotation/owner":             "storage-team",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("expected %s=%q, got %q", key, value, got[key])
+		}
+	}
+
+	pv.Spec.StorageClassName = "io2"
+	if got := requestContext(pv, pvc, RequestedByAnnotation, nil, nil)[ContextKeyStorageClassName]; got != "io2" {
+		t.Errorf("expected the StorageClass of the PV to be preferred, got %q", got)
+	}
+}
+
+func TestModifyPVC_PassesRequestContext(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+		"ebs.csi.aws.com/plan": "gp3-migration",
+	})
+	pvc.Labels = map[string]string{"team": "storage"}
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	setupControllerWithClient(t, driverName, client, []Option{WithRequestContextPassThrough([]string{"team"}, nil)}, pvc, pv)
+	waitForModifyCount(t, client, 1, 3*time.Second)
+
+	reqContext := client.GetReqContext()
+	if reqContext[ContextKeyPVCName] != "data" || reqContext[ContextKeyPVName] != "testPV" {
+		t.Errorf("expected PVC and PV names in the context, got %v", reqContext)
+	}
+	if reqContext[ContextKeyRequestedBy] != "VolumeModificationPlan/gp3-migration" {
+		t.Errorf("expected modification to be attributed to the plan, got %q", reqContext[ContextKeyRequestedBy])
+	}
+	if reqContext[ContextKeyPVCLabelPrefix+"team"] != "storage" {
+		t.Errorf("expected label to be passed through, got %v", reqContext)
+	}
+}
+
+func TestVolumeModification_PassesRequestContext(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{"owner": "storage-team"})
+	pvc.Labels = map[string]string{"team": "storage"}
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+	vm := newTestVolumeModification("to-gp3", "data", map[string]string{"type": "gp3"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	setupVolumeModificationController(t, driverName, client,
+		[]Option{WithRequestContextPassThrough([]string{"team"}, []string{"owner"})},
+		[]*v1alpha1.VolumeModification{vm}, pvc, pv)
+	waitForModifyCount(t, client, 1, 3*time.Second)
+
+	reqContext := client.GetReqContext()
+	if reqContext[ContextKeyRequestedBy] != "VolumeModification/to-gp3" {
+		t.Errorf("expected modification to be attributed to the VolumeModification, got %q", reqContext[ContextKeyRequestedBy])
+	}
+	if reqContext[ContextKeyPVCLabelPrefix+"team"] != "storage" || reqContext[ContextKeyPVCAnnotationPrefix+"owner"] != "storage-team" {
+		t.Errorf("expected label and annotation to be passed through, got %v", reqContext)
+	}
+}
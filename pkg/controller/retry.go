@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+)
+
+// isTerminalError reports whether err is a failure of the driver that
+// retrying the same modification cannot fix.
+func isTerminalError(err error) bool {
+	var modErr *csi.ModificationError
+	return errors.As(err, &modErr) && modErr.Terminal()
+}
+
+// retryAfter returns the delay the driver asked to wait before retrying the
+// modification that failed with err, or zero to retry with backoff.
+func retryAfter(err error) time.Duration {
+	var modErr *csi.ModificationError
+	if errors.As(err, &modErr) {
+		return modErr.RetryAfter
+	}
+	return 0
+}
+
+// failureClassification returns FailureTerminal or FailureRetryable for a
+// modification rejected by the driver with err, or an empty string if it is
+// not retried either.
+func (c *modifyController) failureClassification(err error) string {
+	switch {
+	case isTerminalError(err):
+		return FailureTerminal
+	case c.retryFailures:
+		return FailureRetryable
+	default:
+		return ""
+	}
+}
+
+// modificationFailureMessage describes a failed modification of volume for
+// an event, including whether and when it is retried.
+func (c *modifyController) modificationFailureMessage(volume string, err error) string {
+	var modErr *csi.ModificationError
+	isModErr := errors.As(err, &modErr)
+	switch {
+	case isModErr && modErr.Terminal():
+		return fmt.Sprintf("Modification of %s failed with terminal error %s, not retrying until the annotations change: %v", volume, modErr.Code, err)
+	case !c.retryFailures:
+		return fmt.Sprintf("Modification of %s failed: %v", volume, err)
+	case !isModErr:
+		return fmt.Sprintf("Modification of %s failed, retrying with backoff: %v", volume, err)
+	case modErr.RetryAfter > 0:
+		return fmt.Sprintf("Modification of %s failed with retryable error %s, retrying in %s as requested by the driver: %v", volume, modErr.Code, modErr.RetryAfter, err)
+	default:
+		return fmt.Sprintf("Modification of %s failed with retryable error %s, retrying with backoff: %v", volume, modErr.Code, err)
+	}
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// setupRetryingController starts a controller retrying failed modifications
+// with the given backoff.
+func setupRetryingController(t *testing.T, driverName string, client *csi.FakeClient, backoff time.Duration, objects ...runtime.Object) *modifyController {
+	t.Helper()
+	return startController(t, driverName, client, workqueue.NewItemExponentialFailureRateLimiter(backoff, backoff), true, nil, objects...)
+}
+
+func TestModifyPVC_TerminalErrorNotRetried(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{"ebs.csi.aws.com/iops": "100"})
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyError(status.Error(codes.InvalidArgument, "iops must be at least 3000"))
+	ctrl := setupRetryingController(t, driverName, client, time.Millisecond, pvc, pv)
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationFailed, 3*time.Second)
+	if !strings.Contains(event.Message, "terminal error InvalidArgument, not retrying") {
+		t.Fatalf("expected event to report a terminal error, got %q", event.Message)
+	}
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "data", "iops", ModificationStateFailed, 3*time.Second)
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected terminal error not to be retried, got %d modify calls", client.GetModifyCallCount())
+	}
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "data", "iops", ModificationStateFailed, 3*time.Second)
+	if status.Failure != FailureTerminal {
+		t.Fatalf("expected the failure to be recorded as terminal, got %+v", status)
+	}
+
+	// Requeues, e.g. by drift checks, quota changes or a restart, do not
+	// issue the modification again.
+	ctrl.markDrifted("default/data")
+	ctrl.claimQueue.Add("default/data")
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected requeued terminal error not to be retried, got %d modify calls", client.GetModifyCallCount())
+	}
+
+	// Changing the annotations retries the modification.
+	client.SetModifyError(nil)
+	updated, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "data", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated.Annotations["ebs.csi.aws.com/iops"] = "3000"
+	updated.ResourceVersion = "2"
+	if _, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForModifyCount(t, client, 2, 3*time.Second)
+}
+
+func TestModifyPVC_RetryAfter(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+
+	throttled, err := status.New(codes.ResourceExhausted, "request rate exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(100 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyError(throttled.Err())
+	// The backoff is longer than the test, so only the driver's delay can
+	// retry the modification in time.
+	ctrl := setupRetryingController(t, driverName, client, time.Hour, pvc, pv)
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationFailed, 3*time.Second)
+	if !strings.Contains(event.Message, "retryable error ResourceExhausted, retrying in 100ms") {
+		t.Fatalf("expected event to report the retry delay, got %q", event.Message)
+	}
+	client.SetModifyError(nil)
+	waitForModifyCount(t, client, 2, 3*time.Second)
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "data", "iops", ModificationStateSucceeded, 3*time.Second)
+}
+
+func TestModifyPVC_RetryAfterResetsBackoff(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+
+	throttled, err := status.New(codes.ResourceExhausted, "request rate exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(10 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyError(status.Error(codes.Internal, "timeout"))
+	ctrl := setupRetryingController(t, driverName, client, 200*time.Millisecond, pvc, pv)
+
+	// The first failure is retried with backoff, the next ones after the
+	// delay asked for by the driver, which forgets the earlier failures.
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	client.SetModifyError(throttled.Err())
+	waitForModifyCount(t, client, 2, 3*time.Second)
+	deadline := time.After(3 * time.Second)
+	for ctrl.claimQueue.NumRequeues("default/data") != 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the backoff to be reset by the driver's delay, got %d requeues", ctrl.claimQueue.NumRequeues("default/data"))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestModificationFailureMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		retryFailures bool
+		expected      string
+	}{
+		{
+			name:          "terminal",
+			err:           status.Error(codes.InvalidArgument, "bad iops"),
+			retryFailures: true,
+			expected:      "Modification of pv-1 failed with terminal error InvalidArgument, not retrying until the annotations change: rpc error: code = InvalidArgument desc = bad iops",
+		},
+		{
+			name:          "retryable",
+			err:           status.Error(codes.Internal, "timeout"),
+			retryFailures: true,
+			expected:      "Modification of pv-1 failed with retryable error Internal, retrying with backoff: rpc error: code = Internal desc = timeout",
+		},
+		{
+			name:          "without status",
+			err:           errors.New("volume ID is empty"),
+			retryFailures: true,
+			expected:      "Modification of pv-1 failed, retrying with backoff: volume ID is empty",
+		},
+		{
+			name:     "retries disabled",
+			err:      status.Error(codes.Internal, "timeout"),
+			expected: "Modification of pv-1 failed: rpc error: code = Internal desc = timeout",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+			client.SetModifyError(tc.err)
+			_, err := client.Modify(context.TODO(), "vol-1", nil, nil, nil)
+			c := &modifyController{retryFailures: tc.retryFailures}
+			if got := c.modificationFailureMessage("pv-1", err); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// recordPreviousParameters records on newPV the values the parameters changed
+// by a modification had on pv, so that the modification can be rolled back.
+// Parameters that were never set cannot be restored and are not recorded.
+// The record is left untouched if the modification changes nothing.
+func recordPreviousParameters(driverName string, pv, newPV *v1.PersistentVolume, params map[string]string) {
+	previous := make(map[string]string)
+	changed := false
+	for key, value := range params {
+		old, ok := pv.Annotations[fmt.Sprintf("%s/%s", driverName, key)]
+		if ok && old == value {
+			continue
+		}
+		changed = true
+		if ok {
+			previous[key] = old
+		}
+	}
+	if !changed {
+		return
+	}
+
+	annotation := fmt.Sprintf(AnnotationPreviousParametersPattern, driverName)
+	if len(previous) == 0 {
+		delete(newPV.Annotations, annotation)
+		return
+	}
+	data, err := json.Marshal(previous)
+	if err != nil {
+		klog.ErrorS(err, "Cannot record previous parameters", "pv", pv.Name)
+		return
+	}
+	newPV.Annotations[annotation] = string(data)
+}
+
+// isRollbackRequested reports whether the "<driver-name>/rollback"
+// annotation of the PVC is set to "true".
+func (c *modifyController) isRollbackRequested(pvc *v1.PersistentVolumeClaim) bool {
+	rollback, _ := strconv.ParseBool(pvc.Annotations[fmt.Sprintf(AnnotationRollbackPattern, c.name)])
+	return rollback
+}
+
+// rollbackPVC sets the "<driver-name>/<key>" annotations of the PVC back to
+// the parameters recorded on the PV before its last modification and clears
+// the rollback request. The returned PVC is then modified as any other
+// request, subject to the same validation, cooldown and quotas. It returns
+// nil if there is nothing to roll back to.
+func (c *modifyController) rollbackPVC(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error) {
+	var previous map[string]string
+	value, ok := pv.Annotations[fmt.Sprintf(AnnotationPreviousParametersPattern, c.name)]
+	if ok {
+		if err := json.Unmarshal([]byte(value), &previous); err != nil {
+			klog.ErrorS(err, "Ignoring invalid previous parameters", "pv", pv.Name, "value", value)
+		}
+	}
+
+	newPVC := pvc.DeepCopy()
+	delete(newPVC.Annotations, fmt.Sprintf(AnnotationRollbackPattern, c.name))
+	if len(previous) == 0 {
+		updatedPVC, err := c.patchPVC(pvc, newPVC)
+		if err != nil {
+			return nil, err
+		}
+		c.eventRecorder.Eventf(updatedPVC, v1.EventTypeWarning, VolumeModificationRollbackFailed, "Cannot roll back volume %s: no previous parameters are recorded", pv.Name)
+		return nil, nil
+	}
+
+	for key, value := range previous {
+		newPVC.Annotations[fmt.Sprintf("%s/%s", c.name, key)] = value
+	}
+	updatedPVC, err := c.patchPVC(pvc, newPVC)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(previous))
+	for key, value := range previous {
+		values = append(values, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(values)
+	klog.InfoS("Rolling back volume modification", "pvc", util.PVCKey(pvc), "parameters", previous)
+	c.eventRecorder.Eventf(updatedPVC, v1.EventTypeNormal, VolumeModificationRollback, "Rolling back volume %s to %s", pv.Name, strings.Join(values, ", "))
+	c.markRequested(util.PVCKey(pvc))
+	return updatedPVC, nil
+}
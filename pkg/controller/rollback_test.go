@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordPreviousParameters(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	tests := []struct {
+		name        string
+		applied     map[string]string
+		recorded    string
+		params      map[string]string
+		expected    map[string]string
+		expectUnset bool
+	}{
+		{
+			name:     "changed parameters",
+			applied:  map[string]string{"ebs.csi.aws.com/iops": "3000", "ebs.csi.aws.com/type": "gp3"},
+			params:   map[string]string{"iops": "5000", "type": "gp3"},
+			expected: map[string]string{"iops": "3000"},
+		},
+		{
+			name:     "parameter never set",
+			applied:  map[string]string{"ebs.csi.aws.com/iops": "3000"},
+			params:   map[string]string{"iops": "5000", "throughput": "250"},
+			expected: map[string]string{"iops": "3000"},
+		},
+		{
+			name:        "only parameters never set",
+			recorded:    `{"iops":"3000"}`,
+			params:      map[string]string{"throughput": "250"},
+			expectUnset: true,
+		},
+		{
+			name:     "nothing changed",
+			applied:  map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			recorded: `{"iops":"3000"}`,
+			params:   map[string]string{"iops": "5000"},
+			expected: map[string]string{"iops": "3000"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pv := newTestPV("testPV", "", "", "", driverName)
+			for key, value := range tc.applied {
+				pv.Annotations[key] = value
+			}
+			if tc.recorded != "" {
+				pv.Annotations["ebs.csi.aws.com/previous-parameters"] = tc.recorded
+			}
+			newPV := pv.DeepCopy()
+
+			recordPreviousParameters(driverName, pv, newPV, tc.params)
+
+			value, ok := newPV.Annotations["ebs.csi.aws.com/previous-parameters"]
+			if tc.expectUnset {
+				if ok {
+					t.Fatalf("expected previous parameters to be removed, got %q", value)
+				}
+				return
+			}
+			var got map[string]string
+			if err := json.Unmarshal([]byte(value), &got); err != nil {
+				t.Fatalf("invalid previous parameters %q: %v", value, err)
+			}
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+			for key, value := range tc.expected {
+				if got[key] != value {
+					t.Fatalf("expected %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func newRollbackTestObjects(driverName string, previous string) (*v1.PersistentVolumeClaim, *v1.PersistentVolume) {
+	pvc := newTestPVC("rollback-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":     "5000",
+		"ebs.csi.aws.com/rollback": "true",
+	})
+	pv := newTestPV("testPV", "rollback-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/iops"] = "5000"
+	if previous != "" {
+		pv.Annotations["ebs.csi.aws.com/previous-parameters"] = previous
+	}
+	return pvc, pv
+}
+
+func TestRollbackPVC(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	tests := []struct {
+		name         string
+		previous     string
+		expectedPVC  bool
+		expectedIOPS string
+	}{
+		{
+			name:         "previous parameters recorded",
+			previous:     `{"iops":"3000"}`,
+			expectedPVC:  true,
+			expectedIOPS: "3000",
+		},
+		{
+			name:         "nothing recorded",
+			expectedIOPS: "5000",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc, pv := newRollbackTestObjects(driverName, tc.previous)
+			ctrl := newTestController(driverName)
+			ctrl.kubeClient = fake.NewClientset(pvc)
+			ctrl.eventRecorder = record.NewFakeRecorder(10)
+			ctrl.claims = cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if err := ctrl.claims.Add(pvc); err != nil {
+				t.Fatal(err)
+			}
+
+			updated, err := ctrl.rollbackPVC(pv, pvc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (updated != nil) != tc.expectedPVC {
+				t.Fatalf("expected a PVC to modify: %v, got %v", tc.expectedPVC, updated)
+			}
+
+			// The next sync of the PVC sees the rollback without waiting for
+			// the informer.
+			obj, _, err := ctrl.claims.GetByKey("default/rollback-pvc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cached := obj.(*v1.PersistentVolumeClaim)
+			if _, ok := cached.Annotations["ebs.csi.aws.com/rollback"]; ok {
+				t.Error("expected the rollback request to be cleared in the cache")
+			}
+			if got := cached.Annotations["ebs.csi.aws.com/iops"]; got != tc.expectedIOPS {
+				t.Errorf("expected cached iops annotation %q, got %q", tc.expectedIOPS, got)
+			}
+		})
+	}
+}
+
+func TestRollback_RestoresPreviousParameters(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc, pv := newRollbackTestObjects(driverName, `{"iops":"3000"}`)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	if got := client.GetParams()["iops"]; got != "3000" {
+		t.Fatalf("expected volume to be modified back to 3000 IOPS, got %q", got)
+	}
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationRollback, 3*time.Second)
+	if !strings.Contains(event.Message, "iops=3000") {
+		t.Fatalf("expected event to list the restored parameters, got %q", event.Message)
+	}
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "rollback-pvc", "iops", ModificationStateSucceeded, 3*time.Second)
+
+	updatedPVC, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "rollback-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedPVC.Annotations["ebs.csi.aws.com/iops"] != "3000" {
+		t.Errorf("expected PVC annotation to be rolled back, got %q", updatedPVC.Annotations["ebs.csi.aws.com/iops"])
+	}
+	if _, ok := updatedPVC.Annotations["ebs.csi.aws.com/rollback"]; ok {
+		t.Error("expected rollback request to be cleared")
+	}
+
+	updatedPV, err := ctrl.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedPV.Annotations["ebs.csi.aws.com/iops"] != "3000" || updatedPV.Annotations["ebs.csi.aws.com/previous-parameters"] != `{"iops":"5000"}` {
+		t.Errorf("unexpected PV annotations %v", updatedPV.Annotations)
+	}
+}
+
+func TestRollback_NothingRecorded(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc, pv := newRollbackTestObjects(driverName, "")
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	waitForEvent(t, ctrl.kubeClient, VolumeModificationRollbackFailed, 3*time.Second)
+	waitForQueueDrain(t, ctrl, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call without previous parameters, got %d", client.GetModifyCallCount())
+	}
+	updatedPVC, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "rollback-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := updatedPVC.Annotations["ebs.csi.aws.com/rollback"]; ok {
+		t.Error("expected rollback request to be cleared")
+	}
+}
+
+func TestRollback_RespectsCooldown(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc, pv := newRollbackTestObjects(driverName, `{"iops":"3000"}`)
+	pv.Annotations["ebs.csi.aws.com/last-modification-time"] = time.Now().UTC().Format(time.RFC3339)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, []Option{WithModificationCooldown(time.Hour)}, pvc, pv)
+
+	waitForEvent(t, ctrl.kubeClient, VolumeModificationRollback, 3*time.Second)
+	waitForEvent(t, ctrl.kubeClient, VolumeModificationDeferred, 3*time.Second)
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "rollback-pvc", "iops", ModificationStatePending, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected rollback to wait for the cooldown, got %d modify calls", client.GetModifyCallCount())
+	}
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// maintenanceWindow is the value of the "<driver-name>/maintenance-window"
+// annotation. It is either a recurring window, given as a cron expression
+// evaluated in UTC followed by the duration of the window, e.g.
+// "0 2 * * SAT 4h", or a single RFC3339 interval, e.g.
+// "2026-10-17T02:00:00Z/2026-10-17T06:00:00Z".
+type maintenanceWindow struct {
+	schedule *util.CronSchedule
+	duration time.Duration
+
+	start, end time.Time
+}
+
+func parseMaintenanceWindow(value string) (*maintenanceWindow, error) {
+	if start, end, ok := strings.Cut(value, "/"); ok && !strings.Contains(value, " ") {
+		startTime, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of maintenance window %q: %w", value, err)
+		}
+		endTime, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of maintenance window %q: %w", value, err)
+		}
+		if !endTime.After(startTime) {
+			return nil, fmt.Errorf("maintenance window %q ends before it starts", value)
+		}
+		return &maintenanceWindow{start: startTime, end: endTime}, nil
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("maintenance window %q must be a cron expression followed by a duration, or an RFC3339 interval", value)
+	}
+	duration, err := time.ParseDuration(fields[5])
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration of maintenance window %q", value)
+	}
+	schedule, err := util.ParseCronSchedule(strings.Join(fields[:5], " "))
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window %q: %w", value, err)
+	}
+	return &maintenanceWindow{schedule: schedule, duration: duration}, nil
+}
+
+// next returns the first time at or after t within the window. The zero time
+// is returned if the window never opens again.
+func (w *maintenanceWindow) next(t time.Time) time.Time {
+	if w.schedule == nil {
+		switch {
+		case t.Before(w.start):
+			return w.start
+		case t.Before(w.end):
+			return t
+		default:
+			return time.Time{}
+		}
+	}
+
+	t = t.UTC()
+	// The window is open if it started less than its duration ago.
+	opening := w.schedule.Next(t.Add(-w.duration))
+	switch {
+	case opening.IsZero():
+		return time.Time{}
+	case !opening.After(t):
+		return t
+	default:
+		return opening
+	}
+}
+
+// scheduledModificationTime returns when the modification requested on the
+// PVC may start according to its "<driver-name>/modify-after" and
+// "<driver-name>/maintenance-window" annotations. The zero time is returned
+// if it may start now.
+func (c *modifyController) scheduledModificationTime(pvc *v1.PersistentVolumeClaim, now time.Time) (time.Time, error) {
+	return scheduledTime(c.name, pvc.Annotations, now)
+}
+
+// ValidateSchedule returns an error if the "<driver-name>/modify-after" or
+// "<driver-name>/maintenance-window" annotation cannot be parsed, or if the
+// maintenance window has ended.
+func ValidateSchedule(driverName string, annotations map[string]string, now time.Time) error {
+	_, err := scheduledTime(driverName, annotations, now)
+	return err
+}
+
+func scheduledTime(driverName string, annotations map[string]string, now time.Time) (time.Time, error) {
+	earliest := now
+	if value, ok := annotations[fmt.Sprintf(AnnotationModifyAfterPattern, driverName)]; ok {
+		modifyAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid modify-after time %q: %w", value, err)
+		}
+		if modifyAfter.After(earliest) {
+			earliest = modifyAfter
+		}
+	}
+
+	if value, ok := annotations[fmt.Sprintf(AnnotationMaintenanceWindowPattern, driverName)]; ok {
+		window, err := parseMaintenanceWindow(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		earliest = window.next(earliest)
+		if earliest.IsZero() {
+			return time.Time{}, fmt.Errorf("maintenance window %q has ended", value)
+		}
+	}
+
+	if !earliest.After(now) {
+		return time.Time{}, nil
+	}
+	return earliest, nil
+}
+
+// refuseSchedule reports an invalid schedule on the PVC. Retrying cannot help
+// until the annotations are changed, which requeues the PVC, so the failure
+// is only reported the first time the PVC is synced with that schedule.
+func (c *modifyController) refuseSchedule(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, err error) {
+	params := c.paramsFromAnnotations(pvc)
+	if c.hasModificationStatus(pvc, params, ModificationStateFailed, err.Error()) {
+		klog.V(4).InfoS("Invalid schedule already reported", "pvc", util.PVCKey(pvc))
+		return
+	}
+	c.eventRecorder.Eventf(pvc, v1.EventTypeWarning, VolumeModificationFailed, "Refusing to modify %s because of an invalid schedule: %v", pv.Name, err)
+	c.updateModificationStatus(pvc, params, ModificationStateFailed, err.Error())
+}
+
+// deferForSchedule requeues the PVC for when its modification is scheduled.
+// The schedule is reported only when the scheduled time changes, not on every
+// resync of the PVC.
+func (c *modifyController) deferForSchedule(pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, scheduled time.Time) {
+	params := c.paramsFromAnnotations(pvc)
+	reason := fmt.Sprintf("Scheduled for %s", scheduled.Format(time.RFC3339))
+	if c.hasModificationStatus(pvc, params, ModificationStatePending, reason) {
+		klog.V(4).InfoS("Schedule already reported", "pvc", util.PVCKey(pvc), "scheduled", scheduled)
+	} else {
+		klog.InfoS("Deferring modification until its scheduled time", "pvc", util.PVCKey(pvc), "scheduled", scheduled)
+		c.eventRecorder.Eventf(pvc, v1.EventTypeNormal, VolumeModificationScheduled, "Modification of volume %s is scheduled for %s", pv.Name, scheduled.Format(time.RFC3339))
+		c.updateModificationStatus(pvc, params, ModificationStatePending, reason)
+	}
+	c.claimQueue.AddAfter(util.PVCKey(pvc), time.Until(scheduled))
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestScheduledModificationTime(t *testing.T) {
+	// A Friday.
+	now := time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    time.Time
+		expectErr   bool
+	}{
+		{
+			name: "not scheduled",
+		},
+		{
+			name:        "modify-after in the past",
+			annotations: map[string]string{"ebs.csi.aws.com/modify-after": "2026-10-16T18:00:00Z"},
+		},
+		{
+			name:        "modify-after in the future",
+			annotations: map[string]string{"ebs.csi.aws.com/modify-after": "2026-10-17T00:00:00Z"},
+			expected:    time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "inside recurring window",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "0 18 * * FRI 1h"},
+		},
+		{
+			name:        "before recurring window",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "0 2 * * SAT 4h"},
+			expected:    time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "after recurring window",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "0 18 * * FRI 30m"},
+			expected:    time.Date(2026, 10, 23, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "window after modify-after",
+			annotations: map[string]string{
+				"ebs.csi.aws.com/modify-after":       "2026-10-17T07:00:00Z",
+				"ebs.csi.aws.com/maintenance-window": "0 2 * * * 4h",
+			},
+			expected: time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "modify-after inside window",
+			annotations: map[string]string{
+				"ebs.csi.aws.com/modify-after":       "2026-10-17T03:00:00Z",
+				"ebs.csi.aws.com/maintenance-window": "0 2 * * * 4h",
+			},
+			expected: time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "inside interval",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "2026-10-16T18:00:00Z/2026-10-16T19:00:00Z"},
+		},
+		{
+			name:        "before interval",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "2026-10-17T02:00:00Z/2026-10-17T06:00:00Z"},
+			expected:    time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "interval has ended",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "2026-10-16T02:00:00Z/2026-10-16T06:00:00Z"},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid modify-after",
+			annotations: map[string]string{"ebs.csi.aws.com/modify-after": "tomorrow"},
+			expectErr:   true,
+		},
+		{
+			name:        "window without duration",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "0 2 * * SAT"},
+			expectErr:   true,
+		},
+		{
+			name:        "window with invalid cron expression",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "0 25 * * SAT 4h"},
+			expectErr:   true,
+		},
+		{
+			name:        "interval ending before it starts",
+			annotations: map[string]string{"ebs.csi.aws.com/maintenance-window": "2026-10-17T06:00:00Z/2026-10-17T02:00:00Z"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := newTestController("ebs.csi.aws.com")
+			pvc := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+
+			got, err := ctrl.scheduledModificationTime(pvc, now)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tc.expected) {
+				t.Errorf("scheduledModificationTime() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSchedule_DefersModification(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	scheduled := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	pvc := newTestPVC("scheduled-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":         "5000",
+		"ebs.csi.aws.com/modify-after": scheduled.Format(time.RFC3339),
+	})
+	pv := newTestPV("testPV", "scheduled-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationScheduled, 3*time.Second)
+	if !strings.Contains(event.Message, scheduled.Format(time.RFC3339)) {
+		t.Fatalf("expected event to state the scheduled time, got %q", event.Message)
+	}
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "scheduled-pvc", "iops", ModificationStatePending, 3*time.Second)
+	if status.Reason != "Scheduled for "+scheduled.Format(time.RFC3339) {
+		t.Fatalf("unexpected pending reason %q", status.Reason)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected modification to be deferred, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestSchedule_AttemptedWhenWindowOpens(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("scheduled-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":         "5000",
+		"ebs.csi.aws.com/modify-after": time.Now().Add(2 * time.Second).UTC().Format(time.RFC3339),
+	})
+	pv := newTestPV("testPV", "scheduled-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	waitForEvent(t, ctrl.kubeClient, VolumeModificationScheduled, 3*time.Second)
+	waitForModifyCount(t, client, 1, 4*time.Second)
+}
+
+func TestSchedule_InvalidScheduleRefused(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("scheduled-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":               "5000",
+		"ebs.csi.aws.com/maintenance-window": "saturday night",
+	})
+	pv := newTestPV("testPV", "scheduled-pvc", "default", "test-uid", driverName)
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv)
+
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "scheduled-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if !strings.Contains(status.Reason, "maintenance window") {
+		t.Fatalf("unexpected failure reason %q", status.Reason)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call for an invalid schedule, got %d", client.GetModifyCallCount())
+	}
+}
+
+func TestSchedule_InvalidScheduleReportedOnce(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("scheduled-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":         "5000",
+		"ebs.csi.aws.com/modify-after": "tomorrow",
+	})
+	pv := newTestPV("testPV", "scheduled-pvc", "default", "test-uid", driverName)
+
+	ctrl := newTestController(driverName)
+	ctrl.kubeClient = fake.NewClientset(pvc, pv)
+	ctrl.claims = cache.NewStore(cache.MetaNamespaceKeyFunc)
+	recorder := record.NewFakeRecorder(10)
+	ctrl.eventRecorder = recorder
+
+	needsModification, _, err := ctrl.pvcNeedsModification(pv, pvc)
+	if err == nil || needsModification {
+		t.Fatalf("expected the invalid schedule to be returned, got %v, %v", needsModification, err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected pvcNeedsModification not to emit events, got %d", len(recorder.Events))
+	}
+
+	// Requeues of the PVC, e.g. by drift checks or after a restart, see the
+	// status recorded by the first sync.
+	ctrl.refuseSchedule(pv, pvc, err)
+	updated, getErr := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "scheduled-pvc", metav1.GetOptions{})
+	if getErr != nil {
+		t.Fatal(getErr)
+	}
+	ctrl.refuseSchedule(pv, updated, err)
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected the invalid schedule to be reported once, got %d events", len(recorder.Events))
+	}
+	if !ctrl.hasModificationStatus(updated, map[string]string{"iops": "5000"}, ModificationStateFailed, err.Error()) {
+		t.Fatalf("expected the failure to be recorded on the PVC, got annotations %v", updated.Annotations)
+	}
+}
+
+func TestSchedule_ScheduleReportedOnce(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	scheduled := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	pvc := newTestPVC("scheduled-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "scheduled-pvc", "default", "test-uid", driverName)
+
+	ctrl := newTestController(driverName)
+	ctrl.kubeClient = fake.NewClientset(pvc, pv)
+	ctrl.claims = cache.NewStore(cache.MetaNamespaceKeyFunc)
+	recorder := record.NewFakeRecorder(10)
+	ctrl.eventRecorder = recorder
+
+	get := func() *v1.PersistentVolumeClaim {
+		updated, err := ctrl.kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "scheduled-pvc", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return updated
+	}
+
+	// Resyncs of the PVC see the schedule recorded by the first sync.
+	ctrl.deferForSchedule(pv, pvc, scheduled)
+	ctrl.deferForSchedule(pv, get(), scheduled)
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected the schedule to be reported once, got %d events", len(recorder.Events))
+	}
+
+	// A new scheduled time is reported again.
+	rescheduled := scheduled.Add(time.Hour)
+	ctrl.deferForSchedule(pv, get(), rescheduled)
+	if len(recorder.Events) != 2 {
+		t.Fatalf("expected the new schedule to be reported, got %d events", len(recorder.Events))
+	}
+	if !ctrl.hasModificationStatus(get(), map[string]string{"iops": "5000"}, ModificationStatePending, "Scheduled for "+rescheduled.Format(time.RFC3339)) {
+		t.Fatalf("expected the new schedule to be recorded on the PVC, got annotations %v", get().Annotations)
+	}
+	if ctrl.claimQueue.Len() != 0 {
+		t.Fatalf("expected the PVC to be requeued only when the schedule is due, got %d queued", ctrl.claimQueue.Len())
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StorageClass parameters referencing the Secret passed to the driver with
+// every modification of the volumes of the class, like the
+// csi.storage.k8s.io/controller-expand-secret-* parameters of the
+// external-resizer.
+const (
+	ModifierSecretNameKey      = "csi.storage.k8s.io/modifier-secret-name"
+	ModifierSecretNamespaceKey = "csi.storage.k8s.io/modifier-secret-namespace"
+)
+
+// modifierSecretRef returns the Secret referenced by the parameters of the
+// StorageClass, or nil if none is. The name may contain the ${pv.name},
+// ${pvc.namespace}, ${pvc.name} and ${pvc.annotations['<key>']} templates,
+// the namespace the ${pv.name} and ${pvc.namespace} ones.
+func modifierSecretRef(class *storagev1.StorageClass, pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) (*v1.SecretReference, error) {
+	nameTemplate, hasName := class.Parameters[ModifierSecretNameKey]
+	namespaceTemplate, hasNamespace := class.Parameters[ModifierSecretNamespaceKey]
+	if !hasName && !hasNamespace {
+		return nil, nil
+	}
+	if !hasName || !hasNamespace {
+		return nil, fmt.Errorf("%s and %s must be set together in StorageClass %s", ModifierSecretNameKey, ModifierSecretNamespaceKey, class.Name)
+	}
+
+	namespaceParams := map[string]string{
+		"pv.name":       pv.Name,
+		"pvc.namespace": pvc.Namespace,
+	}
+	namespace, err := resolveSecretTemplate(namespaceTemplate, namespaceParams)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s of StorageClass %s: %w", ModifierSecretNamespaceKey, class.Name, err)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return nil, fmt.Errorf("%s of StorageClass %s resolves to invalid namespace %q: %s", ModifierSecretNamespaceKey, class.Name, namespace, strings.Join(errs, ", "))
+	}
+
+	nameParams := map[string]string{
+		"pv.name":       pv.Name,
+		"pvc.namespace": pvc.Namespace,
+		"pvc.name":      pvc.Name,
+	}
+	for key, value := range pvc.Annotations {
+		nameParams[fmt.Sprintf("pvc.annotations['%s']", key)] = value
+	}
+	name, err := resolveSecretTemplate(nameTemplate, nameParams)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s of StorageClass %s: %w", ModifierSecretNameKey, class.Name, err)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, fmt.Errorf("%s of StorageClass %s resolves to invalid name %q: %s", ModifierSecretNameKey, class.Name, name, strings.Join(errs, ", "))
+	}
+
+	return &v1.SecretReference{Name: name, Namespace: namespace}, nil
+}
+
+// resolveSecretTemplate expands the ${...} templates of value from params.
+func resolveSecretTemplate(value string, params map[string]string) (string, error) {
+	var missing []string
+	resolved := os.Expand(value, func(key string) string {
+		resolved, ok := params[key]
+		if !ok {
+			missing = append(missing, key)
+		}
+		return resolved
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("invalid tokens %q", missing)
+	}
+	return resolved, nil
+}
+
+// getModifierSecrets returns the content of the Secret referenced by the
+// StorageClass of the volume, or nil if there is none. The values must not
+// be logged.
+func getModifierSecrets(kubeClient kubernetes.Interface, classes cache.Store, pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) (map[string]string, error) {
+	className := storageClassName(pv, pvc)
+	if className == "" || classes == nil {
+		return nil, nil
+	}
+	obj, exists, err := classes.GetByKey(className)
+	if err != nil || !exists {
+		// The StorageClass may have been deleted after provisioning.
+		return nil, nil
+	}
+	class, ok := obj.(*storagev1.StorageClass)
+	if !ok {
+		return nil, nil
+	}
+
+	ref, err := modifierSecretRef(class, pv, pvc)
+	if err != nil || ref == nil {
+		return nil, err
+	}
+	secret, err := kubeClient.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	secrets := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		secrets[key] = string(value)
+	}
+	return secrets, nil
+}
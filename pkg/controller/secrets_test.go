@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestModifierSecretRef(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "team-a", map[string]string{"team.example.com/secret": "team-a-credentials"})
+	pv := newTestPV("pv-1", "data", "team-a", "test-uid", driverName)
+
+	tests := []struct {
+		name        string
+		parameters  map[string]string
+		expected    *v1.SecretReference
+		expectedErr string
+	}{
+		{
+			name: "no secret",
+		},
+		{
+			name: "static reference",
+			parameters: map[string]string{
+				ModifierSecretNameKey:      "modifier-credentials",
+				ModifierSecretNamespaceKey: "kube-system",
+			},
+			expected: &v1.SecretReference{Name: "modifier-credentials", Namespace: "kube-system"},
+		},
+		{
+			name: "templates",
+			parameters: map[string]string{
+				ModifierSecretNameKey:      "${pvc.name}-${pv.name}",
+				ModifierSecretNamespaceKey: "${pvc.namespace}",
+			},
+			expected: &v1.SecretReference{Name: "data-pv-1", Namespace: "team-a"},
+		},
+		{
+			name: "annotation template",
+			parameters: map[string]string{
+				ModifierSecretNameKey:      "${pvc.annotations['team.example.com/secret']}",
+				ModifierSecretNamespaceKey: "${pvc.namespace}",
+			},
+			expected: &v1.SecretReference{Name: "team-a-credentials", Namespace: "team-a"},
+		},
+		{
+			name: "namespace only",
+			parameters: map[string]string{
+				ModifierSecretNamespaceKey: "kube-system",
+			},
+			expectedErr: "must be set together",
+		},
+		{
+			name: "unsupported namespace template",
+			parameters: map[string]string{
+				ModifierSecretNameKey:      "modifier-credentials",
+				ModifierSecretNamespaceKey: "${pvc.name}",
+			},
+			expectedErr: `invalid tokens ["pvc.name"]`,
+		},
+		{
+			name: "invalid name",
+			parameters: map[string]string{
+				ModifierSecretNameKey:      "${pvc.annotations['missing']}",
+				ModifierSecretNamespaceKey: "kube-system",
+			},
+			expectedErr: "invalid tokens",
+		},
+		{
+			name: "invalid namespace",
+			parameters: map[string]string{
+				ModifierSecretNameKey:      "modifier-credentials",
+				ModifierSecretNamespaceKey: "Kube_System",
+			},
+			expectedErr: `resolves to invalid namespace "Kube_System"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			class := newTestStorageClass("gp3", driverName, "")
+			class.Parameters = tc.parameters
+
+			ref, err := modifierSecretRef(class, pv, pvc)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.expected == nil {
+				if ref != nil {
+					t.Fatalf("expected no secret, got %v", ref)
+				}
+				return
+			}
+			if ref == nil || *ref != *tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, ref)
+			}
+		})
+	}
+}
+
+func newModifierSecretStorageClass(driverName string) runtime.Object {
+	class := newTestStorageClass("gp3", driverName, "")
+	class.Parameters = map[string]string{
+		ModifierSecretNameKey:      "${pvc.name}-credentials",
+		ModifierSecretNamespaceKey: "${pvc.namespace}",
+	}
+	return class
+}
+
+func TestModifyPVC_PassesSecrets(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}
+
+	client := csi.NewFakeClient(driverName, true, false)
+	setupControllerWithClient(t, driverName, client, nil, pvc, pv, newModifierSecretStorageClass(driverName), secret)
+
+	waitForModifyCount(t, client, 1, 3*time.Second)
+	if got := client.GetSecrets()["token"]; got != "s3cr3t" {
+		t.Fatalf("expected secret to be passed to the driver, got %q", got)
+	}
+}
+
+func TestModifyPVC_MissingSecret(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{"ebs.csi.aws.com/iops": "5000"})
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv, newModifierSecretStorageClass(driverName))
+
+	event := waitForEvent(t, ctrl.kubeClient, VolumeModificationFailed, 3*time.Second)
+	if !strings.Contains(event.Message, "cannot get secret default/data-credentials") {
+		t.Fatalf("expected event to name the missing secret, got %q", event.Message)
+	}
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "data", "iops", ModificationStateFailed, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call without the secret, got %d", client.GetModifyCallCount())
+	}
+}
+
+func TestValidatePVC_PassesSecrets(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("data", "default", map[string]string{
+		"ebs.csi.aws.com/iops":    "5000",
+		"ebs.csi.aws.com/dry-run": "true",
+	})
+	pv := newTestPV("testPV", "data", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}
+
+	client := csi.NewFakeClient(driverName, true, false)
+	ctrl := setupControllerWithClient(t, driverName, client, nil, pvc, pv, newModifierSecretStorageClass(driverName), secret)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "data", "iops", ModificationStateValidated, 3*time.Second)
+	if got := client.GetSecrets()["token"]; got != "s3cr3t" {
+		t.Fatalf("expected secret to be passed to the driver for validation, got %q", got)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected dry run not to modify the volume, got %d modify calls", client.GetModifyCallCount())
+	}
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// modificationStatus is the JSON value of the "<driver-name>/<param>-status"
+// annotation maintained on the PVC for every requested parameter.
+type modificationStatus struct {
+	State  string `json:"state"`
+	Value  string `json:"value"`
+	Reason string `json:"reason,omitempty"`
+	// Failure classifies a failed modification rejected by the driver, see
+	// failureClassification.
+	Failure            string      `json:"failure,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// Classifications of the failures reported in modificationStatus.Failure.
+const (
+	// FailureTerminal is a failure that retrying the same modification
+	// cannot fix. It is not retried until the parameters change.
+	FailureTerminal = "terminal"
+
+	// FailureRetryable is a failure retried by the controller.
+	FailureRetryable = "retryable"
+)
+
+// updateModificationStatus records state for every parameter in params on the
+// PVC. Parameters whose status is already up to date are left untouched so
+// that resyncs do not churn the PVC. Failures are logged and the original PVC
+// is returned, as the status is informational only.
+func (c *modifyController) updateModificationStatus(pvc *v1.PersistentVolumeClaim, params map[string]string, state, reason string) *v1.PersistentVolumeClaim {
+	return c.writeModificationStatus(pvc, params, modificationStatus{State: state, Reason: reason})
+}
+
+// updateFailedModificationStatus records on the PVC that the driver rejected
+// the modification of params with err, along with whether it is retried.
+func (c *modifyController) updateFailedModificationStatus(pvc *v1.PersistentVolumeClaim, params map[string]string, err error) *v1.PersistentVolumeClaim {
+	return c.writeModificationStatus(pvc, params, modificationStatus{
+		State:   ModificationStateFailed,
+		Reason:  err.Error(),
+		Failure: c.failureClassification(err),
+	})
+}
+
+func (c *modifyController) writeModificationStatus(pvc *v1.PersistentVolumeClaim, params map[string]string, status modificationStatus) *v1.PersistentVolumeClaim {
+	if len(params) == 0 {
+		return pvc
+	}
+
+	newPVC := pvc.DeepCopy()
+	if newPVC.Annotations == nil {
+		newPVC.Annotations = make(map[string]string)
+	}
+
+	status.LastTransitionTime = metav1.Now()
+	changed := false
+	for key, value := range params {
+		statusKey := fmt.Sprintf(AnnotationStatusPrefixPattern, c.name, key)
+
+		status.Value = value
+		if c.statusRecorded(pvc, key, status) {
+			continue
+		}
+
+		data, err := json.Marshal(status)
+		if err != nil {
+			klog.ErrorS(err, "Failed to encode modification status", "pvc", util.PVCKey(pvc), "param", key)
+			continue
+		}
+		newPVC.Annotations[statusKey] = string(data)
+		changed = true
+	}
+
+	if !changed {
+		return pvc
+	}
+
+	updatedPVC, err := c.patchPVC(pvc, newPVC)
+	if err != nil {
+		klog.ErrorS(err, "Failed to update modification status", "pvc", util.PVCKey(pvc), "state", status.State)
+		return pvc
+	}
+	return updatedPVC
+}
+
+// hasModificationStatus returns true if state is recorded on the PVC for
+// every parameter in params.
+func (c *modifyController) hasModificationStatus(pvc *v1.PersistentVolumeClaim, params map[string]string, state, reason string) bool {
+	for key, value := range params {
+		if !c.statusRecorded(pvc, key, modificationStatus{State: state, Value: value, Reason: reason}) {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorded returns true if the status of the parameter recorded on the
+// PVC matches status, regardless of when it was recorded.
+func (c *modifyController) statusRecorded(pvc *v1.PersistentVolumeClaim, key string, status modificationStatus) bool {
+	current, ok := c.modificationStatus(pvc, key)
+	return ok && current.State == status.State && current.Value == status.Value &&
+		current.Reason == status.Reason && current.Failure == status.Failure
+}
+
+// modificationStatus returns the status of the parameter recorded on the PVC.
+func (c *modifyController) modificationStatus(pvc *v1.PersistentVolumeClaim, key string) (modificationStatus, bool) {
+	var status modificationStatus
+	value, ok := pvc.Annotations[fmt.Sprintf(AnnotationStatusPrefixPattern, c.name, key)]
+	if !ok || json.Unmarshal([]byte(value), &status) != nil {
+		return modificationStatus{}, false
+	}
+	return status, true
+}
+
+// failedTerminally returns true if the driver rejected the modification of
+// every parameter in params to its requested value with a terminal error.
+func (c *modifyController) failedTerminally(pvc *v1.PersistentVolumeClaim, params map[string]string) bool {
+	if len(params) == 0 {
+		return false
+	}
+	for key, value := range params {
+		status, ok := c.modificationStatus(pvc, key)
+		if !ok || status.State != ModificationStateFailed || status.Failure != FailureTerminal || status.Value != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *modifyController) patchPVC(old, new *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error) {
+	patchBytes, err := util.GetPatchData(old, new)
+	if err != nil {
+		return old, fmt.Errorf("can't patch PVC %s as patch data generation failed: %v", util.PVCKey(old), err)
+	}
+
+	updatedPVC, err := c.kubeClient.CoreV1().PersistentVolumeClaims(old.Namespace).
+		Patch(context.TODO(), old.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+	if err != nil {
+		return old, fmt.Errorf("can't patch PVC %s with %v", util.PVCKey(old), err)
+	}
+
+	err = c.claims.Update(updatedPVC)
+	if err != nil {
+		return old, fmt.Errorf("error updating PVC %s in local cache: %v", util.PVCKey(old), err)
+	}
+	return updatedPVC, nil
+}
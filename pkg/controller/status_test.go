@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// waitForModificationStatus polls the PVC until the status annotation of param
+// reaches the expected state or the timeout expires.
+func waitForModificationStatus(t *testing.T, kubeClient kubernetes.Interface, driverName, pvcName, param, expected string, timeout time.Duration) modificationStatus {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		pvc, err := kubeClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), pvcName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var status modificationStatus
+		raw := pvc.Annotations[driverName+"/"+param+"-status"]
+		if err := json.Unmarshal([]byte(raw), &status); err == nil && status.State == expected {
+			return status
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s status %q, got %q", param, expected, raw)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestModificationStatus_Succeeded(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("status-ok-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":       "5000",
+		"ebs.csi.aws.com/volumeType": "io2",
+	})
+	pv := newTestPV("testPV", "status-ok-pvc", "default", "test-uid", driverName)
+
+	ctrl, _ := setupController(t, driverName, false, pvc, pv)
+
+	for param, value := range map[string]string{"iops": "5000", "volumeType": "io2"} {
+		status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "status-ok-pvc", param, ModificationStateSucceeded, 3*time.Second)
+		if status.Value != value {
+			t.Errorf("expected %s status value %q, got %q", param, value, status.Value)
+		}
+		if status.LastTransitionTime.IsZero() {
+			t.Errorf("expected %s status to have a transition time", param)
+		}
+	}
+}
+
+func TestModificationStatus_Failed(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("status-fail-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pv := newTestPV("testPV", "status-fail-pvc", "default", "test-uid", driverName)
+
+	ctrl, _ := setupController(t, driverName, true, pvc, pv)
+
+	status := waitForModificationStatus(t, ctrl.kubeClient, driverName, "status-fail-pvc", "iops", ModificationStateFailed, 3*time.Second)
+	if status.Reason == "" {
+		t.Error("expected failed status to carry a reason")
+	}
+}
+
+func TestModificationStatus_VACConflict(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	vacName := "my-vac"
+	pvc := newTestPVC("status-vac-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "5000",
+	})
+	pvc.Spec.VolumeAttributesClassName = &vacName
+	pv := newTestPV("testPV", "status-vac-pvc", "default", "test-uid", driverName)
+
+	ctrl, _ := setupController(t, driverName, false, pvc, pv)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "status-vac-pvc", "iops", ModificationStateFailed, 3*time.Second)
+}
+
+func TestModificationStatus_PendingUntilBound(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newFakePendingPVCAnnotated()
+	pvc.Name = "status-pending-pvc"
+
+	ctrl, _ := setupController(t, driverName, false, pvc)
+
+	waitForModificationStatus(t, ctrl.kubeClient, driverName, "status-pending-pvc", "iops", ModificationStatePending, 3*time.Second)
+}
+
+func TestUpdateModificationStatus_Unchanged(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	transition := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	data, err := json.Marshal(modificationStatus{
+		State:              ModificationStateSucceeded,
+		Value:              "5000",
+		LastTransitionTime: transition,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pvc := newTestPVC("unchanged-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops":        "5000",
+		"ebs.csi.aws.com/iops-status": string(data),
+	})
+
+	ctrl := newTestController(driverName)
+	ctrl.kubeClient = fake.NewClientset(pvc)
+	ctrl.claims = cache.NewStore(cache.MetaNamespaceKeyFunc)
+
+	updated := ctrl.updateModificationStatus(pvc, map[string]string{"iops": "5000"}, ModificationStateSucceeded, "")
+	if updated != pvc {
+		t.Fatal("expected PVC not to be patched when status is unchanged")
+	}
+
+	updated = ctrl.updateModificationStatus(pvc, map[string]string{"iops": "6000"}, ModificationStateInProgress, "")
+	var status modificationStatus
+	if err := json.Unmarshal([]byte(updated.Annotations["ebs.csi.aws.com/iops-status"]), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.State != ModificationStateInProgress || status.Value != "6000" {
+		t.Fatalf("unexpected status after update: %+v", status)
+	}
+	if !status.LastTransitionTime.After(transition.Time) {
+		t.Fatalf("expected transition time to move forward, got %v", status.LastTransitionTime)
+	}
+}
@@ -1,5 +1,7 @@
 package controller
 
+import "github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+
 const (
 	VolumeModificationStarted = "VolumeModificationStarted"
 
@@ -7,7 +9,100 @@ const (
 
 	VolumeModificationSuccessful = "VolumeModificationSuccessful"
 
+	VolumeModificationValidated = "VolumeModificationValidated"
+
+	VolumeModificationValidationFailed = "VolumeModificationValidationFailed"
+
+	VolumeModificationDrift = "VolumeModificationDrift"
+
+	VolumeModificationDeferred = "VolumeModificationDeferred"
+
+	VolumeModificationScheduled = "VolumeModificationScheduled"
+
+	VolumeModificationRollback = "VolumeModificationRollback"
+
+	VolumeModificationRollbackFailed = "VolumeModificationRollbackFailed"
+
+	VolumeModificationPlanCompleted = "VolumeModificationPlanCompleted"
+
+	VolumeModificationPlanFailed = "VolumeModificationPlanFailed"
+
 	AnnotationPrefixPattern = "%s/"
 
 	AnnotationStatusPrefixPattern = "%s/%s-status"
+
+	// AnnotationDryRunPattern requests validation of the PVC annotations
+	// by the driver instead of a modification when set to "true".
+	AnnotationDryRunPattern = "%s/dry-run"
+
+	// AnnotationLastModificationTimePattern records on the PV when the
+	// volume was last modified successfully, in RFC3339 format.
+	AnnotationLastModificationTimePattern = "%s/last-modification-time"
+
+	// AnnotationModifyAfterPattern holds the RFC3339 time before which the
+	// PVC must not be modified.
+	AnnotationModifyAfterPattern = "%s/modify-after"
+
+	// AnnotationMaintenanceWindowPattern restricts modifications of the PVC
+	// to a maintenance window, see parseMaintenanceWindow.
+	AnnotationMaintenanceWindowPattern = "%s/maintenance-window"
+
+	// AnnotationPlanPattern records on the PVC the VolumeModificationPlan
+	// that set its modification annotations.
+	AnnotationPlanPattern = "%s/plan"
+
+	// AnnotationVolumeModificationPattern records on the PVC the
+	// VolumeModification that set its modification annotations.
+	AnnotationVolumeModificationPattern = "%s/volume-modification"
+
+	// AnnotationRollbackPattern requests on the PVC the restoration of the
+	// parameters recorded by AnnotationPreviousParametersPattern when set
+	// to "true".
+	AnnotationRollbackPattern = "%s/rollback"
+
+	// AnnotationPreviousParametersPattern records on the PV, as a JSON
+	// object, the values the parameters changed by the last modification
+	// had before it.
+	AnnotationPreviousParametersPattern = "%s/previous-parameters"
+
+	// AnnotationModificationHistoryPattern records on the PV the last
+	// modifications of the volume, see util.ModificationRecord.
+	AnnotationModificationHistoryPattern = util.ModificationHistoryAnnotationPattern
+
+	// AnnotationModificationPolicyPattern holds on a StorageClass the
+	// parameters its volumes can be modified to, see modificationPolicy.
+	AnnotationModificationPolicyPattern = "%s/modification-policy"
+
+	// AnnotationOperationPattern records on the PVC, as a JSON object, the
+	// asynchronous modification the driver is still applying, so that it
+	// is polled instead of reissued after a restart.
+	AnnotationOperationPattern = "%s/operation"
+)
+
+// Annotations with the driver prefix that configure the modifier itself
+// and are never sent to the driver as parameters.
+var reservedAnnotationPatterns = []string{
+	AnnotationDryRunPattern,
+	AnnotationLastModificationTimePattern,
+	AnnotationPlanPattern,
+	AnnotationVolumeModificationPattern,
+	AnnotationModifyAfterPattern,
+	AnnotationMaintenanceWindowPattern,
+	AnnotationRollbackPattern,
+	AnnotationPreviousParametersPattern,
+	AnnotationModificationHistoryPattern,
+	AnnotationOperationPattern,
+}
+
+// States reported in the AnnotationStatusPrefixPattern annotations.
+const (
+	ModificationStatePending = "pending"
+
+	ModificationStateInProgress = "in-progress"
+
+	ModificationStateSucceeded = "succeeded"
+
+	ModificationStateFailed = "failed"
+
+	ModificationStateValidated = "validated"
 )
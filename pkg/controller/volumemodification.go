@@ -0,0 +1,495 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	listers "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	csitrans "k8s.io/csi-translation-lib"
+	"k8s.io/klog/v2"
+)
+
+// NewVolumeModificationController returns a controller that applies
+// VolumeModification objects. Like the VolumeModificationPlan controller, it
+// does not call the driver itself: it sets the "<driver-name>/<key>"
+// annotations of the PVC, along with "<driver-name>/volume-modification",
+// which queues the PVC in the ModifyController of the same driver, and
+// follows its progress through the annotations that controller maintains.
+// Parameters the driver does not support are rejected upfront.
+func NewVolumeModificationController(
+	name string,
+	modifier modifier.Modifier,
+	kubeClient kubernetes.Interface,
+	client versioned.Interface,
+	resyncPeriod time.Duration,
+	informerFactory informers.SharedInformerFactory,
+	vmInformerFactory externalversions.SharedInformerFactory,
+	rateLimiter workqueue.RateLimiter,
+) ModifyController {
+	pvInformer := informerFactory.Core().V1().PersistentVolumes()
+	pvcInformer := informerFactory.Core().V1().PersistentVolumeClaims()
+	vmInformer := vmInformerFactory.Volumemodifier().V1alpha1().VolumeModifications()
+	planInformer := vmInformerFactory.Volumemodifier().V1alpha1().VolumeModificationPlans()
+	queue := workqueue.NewNamedRateLimitingQueue(rateLimiter, fmt.Sprintf("%s-volume-modification", name))
+
+	eventScheme := runtime.NewScheme()
+	utilruntime.Must(kubescheme.AddToScheme(eventScheme))
+	utilruntime.Must(v1alpha1.AddToScheme(eventScheme))
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(v1.NamespaceAll)})
+	eventRecorder := eventBroadcaster.NewRecorder(eventScheme, v1.EventSource{Component: fmt.Sprintf("volume-modifier-for-k8s-%s", name)})
+
+	ctrl := &volumeModificationController{
+		name:          name,
+		modifier:      modifier,
+		kubeClient:    kubeClient,
+		client:        client,
+		queue:         queue,
+		eventRecorder: eventRecorder,
+		lister:        vmInformer.Lister(),
+		owners:        claimOwners{name: name, vms: vmInformer.Lister(), plans: planInformer.Lister()},
+		vmSynced:      vmInformer.Informer().HasSynced,
+		planSynced:    planInformer.Informer().HasSynced,
+		pvSynced:      pvInformer.Informer().HasSynced,
+		pvcSynced:     pvcInformer.Informer().HasSynced,
+		volumes:       pvInformer.Informer().GetStore(),
+		claims:        pvcInformer.Informer().GetStore(),
+		written:       make(map[string]*v1alpha1.VolumeModification),
+	}
+
+	ctrl.handlers.addWithResyncPeriod(vmInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.enqueue,
+		UpdateFunc: func(_, new interface{}) { ctrl.enqueue(new) },
+	}, resyncPeriod)
+	// A VolumeModification may be created before its PVC is bound, and its
+	// progress changes with the status annotations of the PVC.
+	ctrl.handlers.add(pvcInformer.Informer(), cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.enqueueForClaim,
+		UpdateFunc: func(_, new interface{}) { ctrl.enqueueForClaim(new) },
+	})
+	informerFactory.Start(wait.NeverStop)
+	vmInformerFactory.Start(wait.NeverStop)
+
+	return ctrl
+}
+
+type volumeModificationController struct {
+	name          string
+	modifier      modifier.Modifier
+	kubeClient    kubernetes.Interface
+	client        versioned.Interface
+	queue         workqueue.RateLimitingInterface
+	eventRecorder record.EventRecorder
+	lister        listers.VolumeModificationLister
+	owners        claimOwners
+	vmSynced      cache.InformerSynced
+	planSynced    cache.InformerSynced
+	pvSynced      cache.InformerSynced
+	pvcSynced     cache.InformerSynced
+	handlers      eventHandlers
+
+	volumes cache.Store
+	claims  cache.Store
+
+	// written holds the last VolumeModification whose status this controller
+	// updated, as the informer may not have observed the update yet when the
+	// object is synced again.
+	writtenMutex sync.Mutex
+	written      map[string]*v1alpha1.VolumeModification
+}
+
+func (c *volumeModificationController) Run(workers int, ctx context.Context) {
+	defer c.queue.ShutDown()
+	defer c.handlers.removeAll()
+
+	klog.InfoS("Starting VolumeModification controller", "name", c.name)
+	defer klog.InfoS("Shutting down VolumeModification controller", "name", c.name)
+
+	stopCh := ctx.Done()
+	if !cache.WaitForCacheSync(stopCh, c.vmSynced, c.planSynced, c.pvSynced, c.pvcSynced) {
+		klog.Errorf("Cannot sync VolumeModification, VolumeModificationPlan, pv or pvc caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.syncVolumeModifications, 0, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (c *volumeModificationController) HasSynced() bool {
+	return c.vmSynced() && c.planSynced() && c.pvSynced() && c.pvcSynced()
+}
+
+func (c *volumeModificationController) enqueue(obj interface{}) {
+	key, err := getObjectKeys(obj)
+	if err != nil {
+		klog.ErrorS(err, "unable to add obj to VolumeModification queue")
+		return
+	}
+	c.queue.Add(key)
+}
+
+// enqueueForClaim queues the VolumeModifications targeting the PVC.
+func (c *volumeModificationController) enqueueForClaim(obj interface{}) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	vms, err := c.lister.VolumeModifications(pvc.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "cannot list VolumeModifications", "namespace", pvc.Namespace)
+		return
+	}
+	for _, vm := range vms {
+		if vm.Spec.PersistentVolumeClaimName == pvc.Name {
+			c.enqueue(vm)
+		}
+	}
+}
+
+func (c *volumeModificationController) syncVolumeModifications() {
+	key, quit := c.queue.Get()
+	if quit {
+		return
+	}
+	defer c.queue.Done(key)
+
+	if err := c.syncVolumeModification(key.(string)); err != nil {
+		klog.ErrorS(err, "error syncing VolumeModification", "key", key)
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+}
+
+func (c *volumeModificationController) syncVolumeModification(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return fmt.Errorf("cannot get namespace and name from key (%s): %w", key, err)
+	}
+
+	vm, err := c.lister.VolumeModifications(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		klog.InfoS("VolumeModification is deleted or does not exist", "namespace", namespace, "name", name)
+		c.forgetWritten(key)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get VolumeModification %s: %w", key, err)
+	}
+	vm = c.latest(vm)
+
+	if isVolumeModificationFinished(vm) {
+		return nil
+	}
+
+	pvcKey := fmt.Sprintf("%s/%s", namespace, vm.Spec.PersistentVolumeClaimName)
+	pvcObject, exists, err := c.claims.GetByKey(pvcKey)
+	if err != nil {
+		return fmt.Errorf("cannot get PVC for key (%s): %w", pvcKey, err)
+	}
+	if !exists {
+		return c.transition(vm, v1alpha1.ReasonPending, fmt.Sprintf("PVC %s not found", vm.Spec.PersistentVolumeClaimName), "")
+	}
+	pvc, ok := pvcObject.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return fmt.Errorf("expected PVC for key (%s) but got %v", pvcKey, pvcObject)
+	}
+	if pvc.Status.Phase != v1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return c.transition(vm, v1alpha1.ReasonPending, fmt.Sprintf("Waiting for PVC %s to be bound", pvc.Name), "")
+	}
+
+	volumeObj, exists, err := c.volumes.GetByKey(pvc.Spec.VolumeName)
+	if err != nil {
+		return fmt.Errorf("get PV %q of pvc %q failed: %v", pvc.Spec.VolumeName, util.PVCKey(pvc), err)
+	}
+	if !exists {
+		if err := c.transition(vm, v1alpha1.ReasonPending, fmt.Sprintf("Waiting for PV %s to be created", pvc.Spec.VolumeName), ""); err != nil {
+			return err
+		}
+		return fmt.Errorf("PV %q bound to PVC %s not found", pvc.Spec.VolumeName, util.PVCKey(pvc))
+	}
+	pv, ok := volumeObj.(*v1.PersistentVolume)
+	if !ok {
+		return fmt.Errorf("expected volume but got %+v", volumeObj)
+	}
+
+	if !ownsVolume(c.name, pv) {
+		klog.V(4).InfoS("Volume is managed by another driver, ignoring VolumeModification", "volumeModification", key, "pv", pv.Name)
+		return nil
+	}
+
+	if message, err := c.rejection(vm, pv, pvc); err != nil || message != "" {
+		if err != nil {
+			return err
+		}
+		return c.transition(vm, v1alpha1.ReasonRejected, message, "")
+	}
+
+	if !c.isRequested(vm, pvc) {
+		if err := c.request(vm, pvc); err != nil {
+			return err
+		}
+		return c.transition(vm, v1alpha1.ReasonInProgress, fmt.Sprintf("Requested modification of volume %s", pv.Name), "")
+	}
+	reason, message, operationID := c.progress(vm, pv, pvc)
+	return c.transition(vm, reason, message, operationID)
+}
+
+// rejection returns why the modification is refused before being requested,
+// if it is.
+func (c *volumeModificationController) rejection(vm *v1alpha1.VolumeModification, pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) (string, error) {
+	if (pvc.Spec.VolumeAttributesClassName != nil && *pvc.Spec.VolumeAttributesClassName != "") ||
+		(pv.Spec.VolumeAttributesClassName != nil && *pv.Spec.VolumeAttributesClassName != "") {
+		vacConflictRefusalsTotal.WithLabelValues(c.name, pvc.Namespace).Inc()
+		return fmt.Sprintf("Refusing to modify %s because PVC %s or its PV has a VAC associated", pv.Name, pvc.Name), nil
+	}
+
+	capability, err := c.modifier.GetModificationCapability()
+	if err != nil {
+		return "", fmt.Errorf("cannot get modification capability of driver %q: %w", c.name, err)
+	}
+	if err := capability.Validate(vm.Spec.Parameters); err != nil {
+		return fmt.Sprintf("Refusing to modify %s because of invalid parameters: %v", pv.Name, err), nil
+	}
+
+	// Another object still modifying the PVC would overwrite the parameters
+	// again, so the first one requested is applied.
+	owner, err := c.owners.active(pvc)
+	if err != nil {
+		return "", err
+	}
+	if owner != "" && owner != kindVolumeModification+"/"+vm.Name {
+		return fmt.Sprintf("Refusing to modify %s because PVC %s is being modified by %s", pv.Name, pvc.Name, owner), nil
+	}
+	return "", nil
+}
+
+// isRequested returns true if the parameters of the VolumeModification are
+// requested on the PVC on its behalf.
+func (c *volumeModificationController) isRequested(vm *v1alpha1.VolumeModification, pvc *v1.PersistentVolumeClaim) bool {
+	if pvc.Annotations[fmt.Sprintf(AnnotationVolumeModificationPattern, c.name)] != vm.Name {
+		return false
+	}
+	for key, value := range vm.Spec.Parameters {
+		if pvc.Annotations[fmt.Sprintf("%s/%s", c.name, key)] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// request sets the parameters of the VolumeModification as the
+// "<driver-name>/<key>" annotations of the PVC.
+func (c *volumeModificationController) request(vm *v1alpha1.VolumeModification, pvc *v1.PersistentVolumeClaim) error {
+	newPVC := pvc.DeepCopy()
+	if newPVC.Annotations == nil {
+		newPVC.Annotations = make(map[string]string)
+	}
+	for key, value := range vm.Spec.Parameters {
+		newPVC.Annotations[fmt.Sprintf("%s/%s", c.name, key)] = value
+	}
+	newPVC.Annotations[fmt.Sprintf(AnnotationVolumeModificationPattern, c.name)] = vm.Name
+	delete(newPVC.Annotations, fmt.Sprintf(AnnotationPlanPattern, c.name))
+
+	patchBytes, err := util.GetPatchData(pvc, newPVC)
+	if err != nil {
+		return fmt.Errorf("can't patch PVC %s as patch data generation failed: %v", util.PVCKey(pvc), err)
+	}
+	// The shared cache is left to the informer, as the ModifyController
+	// only queues PVCs whose annotations changed between two updates.
+	if _, err := c.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).
+		Patch(context.TODO(), pvc.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("can't patch PVC %s with %v", util.PVCKey(pvc), err)
+	}
+	klog.InfoS("Requested modification of PVC by VolumeModification", "pvc", util.PVCKey(pvc), "volumeModification", klog.KObj(vm))
+	return nil
+}
+
+// progress derives the state of a requested modification from the
+// annotations maintained by the ModifyController: the PV records the
+// parameters last applied, and the PVC the status of every requested
+// parameter and the operation the driver is still applying.
+func (c *volumeModificationController) progress(vm *v1alpha1.VolumeModification, pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim) (string, string, string) {
+	applied := true
+	for key, value := range vm.Spec.Parameters {
+		if pv.Annotations[fmt.Sprintf("%s/%s", c.name, key)] != value {
+			applied = false
+		}
+	}
+	if applied {
+		return v1alpha1.ReasonSucceeded, fmt.Sprintf("Volume %s has been modified", pv.Name), ""
+	}
+
+	var operation persistedOperation
+	if value, ok := pvc.Annotations[fmt.Sprintf(AnnotationOperationPattern, c.name)]; ok {
+		if err := json.Unmarshal([]byte(value), &operation); err != nil {
+			klog.ErrorS(err, "Ignoring invalid modification operation", "pvc", util.PVCKey(pvc))
+		}
+	}
+
+	keys := make([]string, 0, len(vm.Spec.Parameters))
+	for key := range vm.Spec.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	reason, message := v1alpha1.ReasonInProgress, fmt.Sprintf("Modifying volume %s", pv.Name)
+	for _, key := range keys {
+		var status modificationStatus
+		if err := json.Unmarshal([]byte(pvc.Annotations[fmt.Sprintf(AnnotationStatusPrefixPattern, c.name, key)]), &status); err != nil ||
+			status.Value != vm.Spec.Parameters[key] {
+			continue
+		}
+		switch {
+		case status.State == ModificationStateFailed && status.Failure == FailureRetryable:
+			// The ModifyController retries the modification with backoff.
+			reason, message = v1alpha1.ReasonInProgress, fmt.Sprintf("Retrying modification of volume %s: %s", pv.Name, status.Reason)
+		case status.State == ModificationStateFailed:
+			return v1alpha1.ReasonFailed, status.Reason, ""
+		case status.State == ModificationStatePending:
+			reason, message = v1alpha1.ReasonPending, status.Reason
+		}
+	}
+	return reason, message, operation.ID
+}
+
+// transition updates the Applied condition of the VolumeModification and
+// reports changes of its reason as events.
+func (c *volumeModificationController) transition(vm *v1alpha1.VolumeModification, reason, message, operationID string) error {
+	var previous string
+	if condition := meta.FindStatusCondition(vm.Status.Conditions, v1alpha1.ConditionApplied); condition != nil && condition.ObservedGeneration == vm.Generation {
+		previous = condition.Reason
+	}
+	if _, err := c.updateStatus(vm, reason, message, operationID); err != nil {
+		return err
+	}
+	if previous == reason {
+		return nil
+	}
+	switch reason {
+	case v1alpha1.ReasonInProgress:
+		c.eventRecorder.Event(vm, v1.EventTypeNormal, VolumeModificationStarted, message)
+	case v1alpha1.ReasonSucceeded:
+		c.eventRecorder.Event(vm, v1.EventTypeNormal, VolumeModificationSuccessful, message)
+	case v1alpha1.ReasonFailed, v1alpha1.ReasonRejected:
+		c.eventRecorder.Event(vm, v1.EventTypeWarning, VolumeModificationFailed, message)
+	}
+	return nil
+}
+
+// updateStatus sets the Applied condition of the VolumeModification for its
+// current generation.
+func (c *volumeModificationController) updateStatus(vm *v1alpha1.VolumeModification, reason, message, operationID string) (*v1alpha1.VolumeModification, error) {
+	newVM := vm.DeepCopy()
+	newVM.Status.ObservedGeneration = vm.Generation
+	newVM.Status.OperationID = operationID
+	status := metav1.ConditionFalse
+	if reason == v1alpha1.ReasonSucceeded {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&newVM.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionApplied,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: vm.Generation,
+	})
+	if equality.Semantic.DeepEqual(vm.Status, newVM.Status) {
+		return vm, nil
+	}
+
+	updated, err := c.client.VolumemodifierV1alpha1().VolumeModifications(vm.Namespace).UpdateStatus(context.TODO(), newVM, metav1.UpdateOptions{})
+	if err != nil {
+		c.forgetWritten(cacheKey(vm))
+		return vm, fmt.Errorf("cannot update status of VolumeModification %s: %w", cacheKey(vm), err)
+	}
+	c.writtenMutex.Lock()
+	c.written[cacheKey(updated)] = updated
+	c.writtenMutex.Unlock()
+	return updated, nil
+}
+
+// latest returns the VolumeModification last written by this controller if it
+// is newer than the one in the informer cache. Status updates do not change
+// the generation, so the written object is preferred until the spec changes.
+func (c *volumeModificationController) latest(vm *v1alpha1.VolumeModification) *v1alpha1.VolumeModification {
+	c.writtenMutex.Lock()
+	defer c.writtenMutex.Unlock()
+	written, ok := c.written[cacheKey(vm)]
+	if !ok {
+		return vm
+	}
+	if written.UID != vm.UID || written.Generation != vm.Generation {
+		delete(c.written, cacheKey(vm))
+		return vm
+	}
+	return written
+}
+
+func (c *volumeModificationController) forgetWritten(key string) {
+	c.writtenMutex.Lock()
+	defer c.writtenMutex.Unlock()
+	delete(c.written, key)
+}
+
+// ownsVolume returns true if the volume is handled by the driver, either
+// natively or through CSI migration.
+func ownsVolume(driverName string, pv *v1.PersistentVolume) bool {
+	if pv.Spec.CSI != nil {
+		return pv.Spec.CSI.Driver == driverName
+	}
+	translator := csitrans.New()
+	inTreeName, err := translator.GetInTreeNameFromCSIName(driverName)
+	if err != nil {
+		return false
+	}
+	pluginName, err := translator.GetInTreePluginNameFromSpec(pv, nil)
+	return err == nil && pluginName == inTreeName
+}
+
+// isVolumeModificationFinished returns true once the current generation of the
+// VolumeModification has been applied or rejected.
+func isVolumeModificationFinished(vm *v1alpha1.VolumeModification) bool {
+	if vm.Status.ObservedGeneration != vm.Generation {
+		return false
+	}
+	condition := meta.FindStatusCondition(vm.Status.Conditions, v1alpha1.ConditionApplied)
+	if condition == nil || condition.ObservedGeneration != vm.Generation {
+		return false
+	}
+	return condition.Reason == v1alpha1.ReasonSucceeded || condition.Reason == v1alpha1.ReasonRejected
+}
+
+func cacheKey(vm *v1alpha1.VolumeModification) string {
+	return fmt.Sprintf("%s/%s", vm.Namespace, vm.Name)
+}
@@ -0,0 +1,467 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	vmfake "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/fake"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// setupVolumeModificationController runs a VolumeModification controller
+// along with the ModifyController it routes modifications through, enforcing
+// quotas as main does. Objects of the volumemodifier API group, such as
+// quotas, are created in its clientset. The VolumeModifications are created
+// once the ModifyController has processed the existing PVCs, so that it
+// notices their modification as an update.
+func setupVolumeModificationController(t *testing.T, driverName string, client *csi.FakeClient, opts []Option, vms []*v1alpha1.VolumeModification, objects ...runtime.Object) (kubernetes.Interface, versioned.Interface) {
+	t.Helper()
+	var kubeObjects, vmObjects []runtime.Object
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *v1alpha1.VolumeModificationQuota, *v1alpha1.VolumeModificationPlan:
+			vmObjects = append(vmObjects, obj)
+		default:
+			kubeObjects = append(kubeObjects, obj)
+		}
+	}
+	k8sClient := fake.NewClientset(kubeObjects...)
+	assignResourceVersions(k8sClient)
+	vmClient := vmfake.NewSimpleClientset(vmObjects...)
+	factory := informers.NewSharedInformerFactory(k8sClient, 0)
+	vmFactory := externalversions.NewSharedInformerFactory(vmClient, 0)
+
+	mod, err := modifier.NewFromClient(driverName, client, k8sClient, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append(opts, WithModificationQuotas(vmFactory))
+	mc := NewModifyController(driverName, mod, k8sClient, 0, factory,
+		workqueue.DefaultControllerRateLimiter(), true, opts...)
+	vmc := NewVolumeModificationController(driverName, mod, k8sClient, vmClient, 0, factory, vmFactory,
+		workqueue.DefaultControllerRateLimiter())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	go NewGroup(mc, vmc).Run(1, ctx)
+	t.Cleanup(cancel)
+
+	waitForCacheSync(t, mc.(*modifyController), 3*time.Second)
+	for _, vm := range vms {
+		if _, err := vmClient.VolumemodifierV1alpha1().VolumeModifications(vm.Namespace).Create(context.TODO(), vm, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return k8sClient, vmClient
+}
+
+// assignResourceVersions makes the PVC watch of the fake clientset report a
+// new resourceVersion on every event, as the API server does, so that the
+// ModifyController notices the annotations the VolumeModification controller
+// patches.
+func assignResourceVersions(k8sClient *fake.Clientset) {
+	var version atomic.Int64
+	k8sClient.PrependWatchReactor("persistentvolumeclaims", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := k8sClient.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		return true, watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+			if accessor, err := meta.Accessor(event.Object); err == nil {
+				accessor.SetResourceVersion(strconv.FormatInt(version.Add(1), 10))
+			}
+			return event, true
+		}), nil
+	})
+}
+
+func newTestVolumeModification(name, pvcName string, params map[string]string) *v1alpha1.VolumeModification {
+	return &v1alpha1.VolumeModification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: v1alpha1.VolumeModificationSpec{
+			PersistentVolumeClaimName: pvcName,
+			Parameters:                params,
+		},
+	}
+}
+
+// waitForAppliedReason polls until the Applied condition of the
+// VolumeModification has the expected reason or the timeout expires.
+func waitForAppliedReason(t *testing.T, vmClient versioned.Interface, name, reason string, timeout time.Duration) *v1alpha1.VolumeModification {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		vm, err := vmClient.VolumemodifierV1alpha1().VolumeModifications("default").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if condition := meta.FindStatusCondition(vm.Status.Conditions, v1alpha1.ConditionApplied); condition != nil && condition.Reason == reason {
+			return vm
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for VolumeModification %s to be %s, got conditions %+v", name, reason, vm.Status.Conditions)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestVolumeModification_Succeeded(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	vm := newTestVolumeModification("to-gp3", "vm-pvc", map[string]string{"type": "gp3", "iops": "5000"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	k8sClient, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	updated := waitForAppliedReason(t, vmClient, "to-gp3", v1alpha1.ReasonSucceeded, 3*time.Second)
+	if updated.Status.ObservedGeneration != 1 {
+		t.Errorf("expected observed generation 1, got %d", updated.Status.ObservedGeneration)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, v1alpha1.ConditionApplied) {
+		t.Errorf("expected Applied condition to be true, got %+v", updated.Status.Conditions)
+	}
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected 1 modify call, got %d", client.GetModifyCallCount())
+	}
+	if got := client.GetParams(); got["type"] != "gp3" || got["iops"] != "5000" {
+		t.Errorf("unexpected params sent to the driver: %v", got)
+	}
+
+	updatedPV, err := k8sClient.CoreV1().PersistentVolumes().Get(context.TODO(), "testPV", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAnnotationsOnPV(updatedPV.Annotations, map[string]string{"ebs.csi.aws.com/type": "gp3", "ebs.csi.aws.com/iops": "5000"}); err != nil {
+		t.Error(err)
+	}
+	updatedPVC, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "vm-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedPVC.Annotations["ebs.csi.aws.com/type"] != "gp3" || updatedPVC.Annotations["ebs.csi.aws.com/iops"] != "5000" ||
+		updatedPVC.Annotations["ebs.csi.aws.com/volume-modification"] != "to-gp3" {
+		t.Errorf("expected the parameters to be requested on the PVC annotations, got %v", updatedPVC.Annotations)
+	}
+	if got := client.GetReqContext()[ContextKeyRequestedBy]; got != "VolumeModification/to-gp3" {
+		t.Errorf("expected the modification to be attributed to the VolumeModification, got %q", got)
+	}
+}
+
+func TestVolumeModification_Async(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	vm := newTestVolumeModification("to-gp3", "vm-pvc", map[string]string{"type": "gp3"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetAsyncModification("op-1",
+		csi.ModificationStatus{State: csi.ModificationInProgress},
+		csi.ModificationStatus{State: csi.ModificationCompleted},
+	)
+	_, vmClient := setupVolumeModificationController(t, driverName, client,
+		[]Option{WithModificationPollInterval(10 * time.Millisecond)}, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	updated := waitForAppliedReason(t, vmClient, "to-gp3", v1alpha1.ReasonSucceeded, 3*time.Second)
+	if updated.Status.OperationID != "" {
+		t.Errorf("expected operation ID to be cleared once completed, got %q", updated.Status.OperationID)
+	}
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected 1 modify call, got %d", client.GetModifyCallCount())
+	}
+	if client.GetStatusCallCount() < 2 {
+		t.Fatalf("expected the operation to be polled until completion, got %d status calls", client.GetStatusCallCount())
+	}
+}
+
+func TestVolumeModification_DeferredByCooldown(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	pv.Annotations["ebs.csi.aws.com/last-modification-time"] = time.Now().UTC().Format(time.RFC3339)
+	vm := newTestVolumeModification("to-gp3", "vm-pvc", map[string]string{"type": "gp3"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	_, vmClient := setupVolumeModificationController(t, driverName, client, []Option{WithModificationCooldown(time.Hour)}, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	updated := waitForAppliedReason(t, vmClient, "to-gp3", v1alpha1.ReasonPending, 3*time.Second)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+	if !strings.Contains(condition.Message, "Waiting for cooldown") {
+		t.Errorf("expected the VolumeModification to report the cooldown, got %q", condition.Message)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected the cooldown to apply to VolumeModifications, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestVolumeModification_PolicyViolationRefused(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	pv.Spec.StorageClassName = "gp3"
+	class := newTestStorageClass("gp3", driverName, `{"iops": {"max": 16000}}`)
+	vm := newTestVolumeModification("fast", "vm-pvc", map[string]string{"iops": "64000"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	_, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv, class)
+
+	updated := waitForAppliedReason(t, vmClient, "fast", v1alpha1.ReasonFailed, 3*time.Second)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+	if !strings.Contains(condition.Message, "value 64000 is above the maximum of 16000") {
+		t.Errorf("expected the VolumeModification to report the policy violation, got %q", condition.Message)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected the StorageClass policy to apply to VolumeModifications, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestVolumeModification_QuotaExceededRefused(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	other := newQuotaTestPV("pv-other", "default", driverName, "60000")
+	quota := newTestQuota("budget", driverName, map[string]int64{"iops": 100000})
+	vm := newTestVolumeModification("fast", "vm-pvc", map[string]string{"iops": "50000"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	_, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv, other, quota)
+
+	updated := waitForAppliedReason(t, vmClient, "fast", v1alpha1.ReasonFailed, 3*time.Second)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+	if !strings.Contains(condition.Message, "iops of VolumeModificationQuota budget would use 110000 of 100000") {
+		t.Errorf("expected the VolumeModification to report the exceeded quota, got %q", condition.Message)
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected quotas to apply to VolumeModifications, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestVolumeModification_TerminalErrorNotRetried(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	vm := newTestVolumeModification("to-io2", "vm-pvc", map[string]string{"type": "io2"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyError(status.Error(codes.InvalidArgument, "type io2 is not available"))
+	_, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	updated := waitForAppliedReason(t, vmClient, "to-io2", v1alpha1.ReasonFailed, 3*time.Second)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+	if !strings.Contains(condition.Message, "type io2 is not available") {
+		t.Errorf("expected the driver's error to be reported, got %q", condition.Message)
+	}
+	if client.GetModifyCallCount() != 1 {
+		t.Fatalf("expected a terminal error not to be retried, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
+func TestVolumeModification_RetryableErrorInProgress(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	vm := newTestVolumeModification("to-io2", "vm-pvc", map[string]string{"type": "io2"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyError(status.Error(codes.Unavailable, "service unavailable"))
+	_, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	// The VolumeModification is InProgress while the modification is
+	// retried, rather than Failed.
+	deadline := time.After(3 * time.Second)
+	for {
+		updated := waitForAppliedReason(t, vmClient, "to-io2", v1alpha1.ReasonInProgress, 3*time.Second)
+		condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+		if strings.Contains(condition.Message, "Retrying modification of volume testPV: ") {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("expected the VolumeModification to report the retry, got %q", condition.Message)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	client.SetModifyError(nil)
+	waitForAppliedReason(t, vmClient, "to-io2", v1alpha1.ReasonSucceeded, 3*time.Second)
+}
+
+func TestVolumeModification_OtherVolumeModificationInProgress(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	first := newTestVolumeModification("first", "vm-pvc", map[string]string{"iops": "4000"})
+
+	// The first VolumeModification is retried for as long as the test runs.
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModifyError(status.Error(codes.Unavailable, "service unavailable"))
+	k8sClient, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{first}, pvc, pv)
+	waitForAppliedReason(t, vmClient, "first", v1alpha1.ReasonInProgress, 3*time.Second)
+
+	second := newTestVolumeModification("second", "vm-pvc", map[string]string{"iops": "5000"})
+	if _, err := vmClient.VolumemodifierV1alpha1().VolumeModifications("default").Create(context.TODO(), second, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	updated := waitForAppliedReason(t, vmClient, "second", v1alpha1.ReasonRejected, 3*time.Second)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+	if !strings.Contains(condition.Message, "is being modified by VolumeModification/first") {
+		t.Errorf("expected the VolumeModification to name the owner of the PVC, got %q", condition.Message)
+	}
+	claim, err := k8sClient.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "vm-pvc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claim.Annotations["ebs.csi.aws.com/iops"] != "4000" || claim.Annotations["ebs.csi.aws.com/volume-modification"] != "first" {
+		t.Fatalf("expected the parameters of the first VolumeModification to be kept, got %v", claim.Annotations)
+	}
+}
+
+func TestVolumeModification_PlanInProgress(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{
+		"ebs.csi.aws.com/iops": "4000",
+		"ebs.csi.aws.com/plan": "rollout",
+	})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	plan := &v1alpha1.VolumeModificationPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollout", Generation: 1},
+		Spec: v1alpha1.VolumeModificationPlanSpec{
+			DriverName: driverName,
+			Parameters: map[string]string{"iops": "4000"},
+		},
+	}
+	vm := newTestVolumeModification("to-io2", "vm-pvc", map[string]string{"type": "io2"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	_, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv, plan)
+
+	updated := waitForAppliedReason(t, vmClient, "to-io2", v1alpha1.ReasonRejected, 3*time.Second)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, v1alpha1.ConditionApplied)
+	if !strings.Contains(condition.Message, "is being modified by VolumeModificationPlan/rollout") {
+		t.Errorf("expected the VolumeModification to name the owner of the PVC, got %q", condition.Message)
+	}
+}
+
+func TestVolumeModification_RejectedParameters(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", driverName)
+	vm := newTestVolumeModification("bad", "vm-pvc", map[string]string{"iopss": "5000"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	client.SetModificationCapability(&csi.ModificationCapability{
+		Parameters: map[string]csi.ParameterSchema{
+			"iops": {Name: "iops", Type: csi.ParameterTypeInteger},
+		},
+	})
+	_, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	waitForAppliedReason(t, vmClient, "bad", v1alpha1.ReasonRejected, 3*time.Second)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call for rejected parameters, got %d", client.GetModifyCallCount())
+	}
+}
+
+func TestVolumeModification_PendingUntilBound(t *testing.T) {
+	driverName := "ebs.csi.aws.com"
+	pvc := newFakePendingPVCAnnotated()
+	pvc.Annotations = map[string]string{}
+	vm := newTestVolumeModification("early", pvc.Name, map[string]string{"type": "gp3"})
+
+	client := csi.NewFakeClient(driverName, true, false)
+	k8sClient, vmClient := setupVolumeModificationController(t, driverName, client, nil, []*v1alpha1.VolumeModification{vm}, pvc)
+	waitForAppliedReason(t, vmClient, "early", v1alpha1.ReasonPending, 3*time.Second)
+
+	pv := newTestPV("testPV", pvc.Name, pvc.Namespace, pvc.UID, driverName)
+	if _, err := k8sClient.CoreV1().PersistentVolumes().Create(context.TODO(), pv, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	bound := pvc.DeepCopy()
+	bound.Spec.VolumeName = pv.Name
+	bound.Status.Phase = "Bound"
+	if _, err := k8sClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(context.TODO(), bound, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForAppliedReason(t, vmClient, "early", v1alpha1.ReasonSucceeded, 3*time.Second)
+}
+
+func TestVolumeModification_OtherDriverIgnored(t *testing.T) {
+	pvc := newTestPVC("vm-pvc", "default", map[string]string{})
+	pv := newTestPV("testPV", "vm-pvc", "default", "test-uid", "other.csi.example.com")
+	vm := newTestVolumeModification("other", "vm-pvc", map[string]string{"type": "gp3"})
+
+	client := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+	_, vmClient := setupVolumeModificationController(t, "ebs.csi.aws.com", client, nil, []*v1alpha1.VolumeModification{vm}, pvc, pv)
+
+	time.Sleep(200 * time.Millisecond)
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected no modify call for a volume of another driver, got %d", client.GetModifyCallCount())
+	}
+	got, err := vmClient.VolumemodifierV1alpha1().VolumeModifications("default").Get(context.TODO(), "other", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Status.Conditions) != 0 {
+		t.Fatalf("expected status to be left to the owning driver, got %+v", got.Status.Conditions)
+	}
+}
+
+func TestIsVolumeModificationFinished(t *testing.T) {
+	tests := []struct {
+		name       string
+		generation int64
+		observed   int64
+		reason     string
+		expected   bool
+	}{
+		{"no status", 1, 0, "", false},
+		{"succeeded", 1, 1, v1alpha1.ReasonSucceeded, true},
+		{"rejected", 1, 1, v1alpha1.ReasonRejected, true},
+		{"failed is retried", 1, 1, v1alpha1.ReasonFailed, false},
+		{"in progress", 1, 1, v1alpha1.ReasonInProgress, false},
+		{"spec changed after success", 2, 1, v1alpha1.ReasonSucceeded, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := newTestVolumeModification("vm", "pvc", nil)
+			vm.Generation = tc.generation
+			vm.Status.ObservedGeneration = tc.observed
+			if tc.reason != "" {
+				meta.SetStatusCondition(&vm.Status.Conditions, metav1.Condition{
+					Type:               v1alpha1.ConditionApplied,
+					Status:             metav1.ConditionFalse,
+					Reason:             tc.reason,
+					ObservedGeneration: tc.observed,
+				})
+			}
+			if got := isVolumeModificationFinished(vm); got != tc.expected {
+				t.Errorf("isVolumeModificationFinished() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
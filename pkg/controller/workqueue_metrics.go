@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/component-base/metrics"
+)
+
+const workqueueSubsystem = "workqueue"
+
+var (
+	workqueueDepth = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "depth",
+		Help:           "Current depth of workqueue",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueAdds = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "adds_total",
+		Help:           "Total number of adds handled by workqueue",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueLatency = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "queue_duration_seconds",
+		Help:           "How long in seconds an item stays in workqueue before being requested.",
+		Buckets:        metrics.ExponentialBuckets(10e-9, 10, 10),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueWorkDuration = metrics.NewHistogramVec(&metrics.HistogramOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "work_duration_seconds",
+		Help:           "How long in seconds processing an item from workqueue takes.",
+		Buckets:        metrics.ExponentialBuckets(10e-9, 10, 10),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueUnfinishedWork = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "unfinished_work_seconds",
+		Help:           "How many seconds of work has been done that is in progress and hasn't been observed by work_duration.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueLongestRunningProcessor = metrics.NewGaugeVec(&metrics.GaugeOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "longest_running_processor_seconds",
+		Help:           "How many seconds has the longest running processor for workqueue been running.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueRetries = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      workqueueSubsystem,
+		Name:           "retries_total",
+		Help:           "Total number of retries handled by workqueue",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"name"})
+
+	workqueueMetrics = []metrics.Registerable{
+		workqueueDepth,
+		workqueueAdds,
+		workqueueLatency,
+		workqueueWorkDuration,
+		workqueueUnfinishedWork,
+		workqueueLongestRunningProcessor,
+		workqueueRetries,
+	}
+)
+
+// workqueueMetricsProvider exposes the metrics of the claim queue on the same
+// registry as the controller metrics.
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
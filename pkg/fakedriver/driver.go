@@ -0,0 +1,319 @@
+// Package fakedriver implements a CSI driver serving the CSI Identity service
+// and the Modify service of modify.proto from an in-memory volume store. It
+// is the reference the sidecar is tested against end to end, over a real
+// gRPC connection.
+package fakedriver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Driver is an in-memory CSI driver. Volumes must be added with AddVolume
+// before they can be modified.
+type Driver struct {
+	csi.UnimplementedIdentityServer
+	modifyrpc.UnimplementedModifyServer
+
+	name string
+
+	parameters    []*modifyrpc.ParameterSchema
+	cooldown      time.Duration
+	latency       time.Duration
+	async         bool
+	asyncDuration time.Duration
+
+	mu         sync.Mutex
+	notReady   bool
+	volumes    map[string]map[string]string
+	failures   map[string]error
+	operations map[string]*operation
+	// pending holds the ID of the operation in progress for each volume.
+	pending  map[string]string
+	lastOpID int
+	requests []*modifyrpc.ModifyVolumePropertiesRequest
+
+	server *grpc.Server
+}
+
+// operation is a modification applied asynchronously.
+type operation struct {
+	volumeID string
+	params   map[string]string
+	done     time.Time
+}
+
+// New returns a driver named name.
+func New(name string, opts ...Option) *Driver {
+	d := &Driver{
+		name:       name,
+		volumes:    make(map[string]map[string]string),
+		failures:   make(map[string]error),
+		operations: make(map[string]*operation),
+		pending:    make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Start serves the driver on the Unix socket at path, which is removed
+// first if it exists.
+func (d *Driver) Start(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot remove socket %s: %w", path, err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", path, err)
+	}
+	d.server = grpc.NewServer()
+	csi.RegisterIdentityServer(d.server, d)
+	modifyrpc.RegisterModifyServer(d.server, d)
+	go d.server.Serve(listener)
+	return nil
+}
+
+// Stop closes the listener and every connection.
+func (d *Driver) Stop() {
+	if d.server != nil {
+		d.server.Stop()
+	}
+}
+
+// AddVolume adds a volume with the given parameters, replacing any volume
+// with the same ID.
+func (d *Driver) AddVolume(volumeID string, params map[string]string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.volumes[volumeID] = maps.Clone(params)
+}
+
+// Volume returns the current parameters of the volume.
+func (d *Driver) Volume(volumeID string) (map[string]string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	params, ok := d.volumes[volumeID]
+	return maps.Clone(params), ok
+}
+
+// SetFailure makes ModifyVolumeProperties fail with err for the volume until
+// it is called again with a nil error.
+func (d *Driver) SetFailure(volumeID string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err == nil {
+		delete(d.failures, volumeID)
+	} else {
+		d.failures[volumeID] = err
+	}
+}
+
+// SetReady sets whether Probe reports the driver ready.
+func (d *Driver) SetReady(ready bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notReady = !ready
+}
+
+// Requests returns the ModifyVolumeProperties requests received so far.
+func (d *Driver) Requests() []*modifyrpc.ModifyVolumePropertiesRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.requests)
+}
+
+func (d *Driver) GetPluginInfo(context.Context, *csi.GetPluginInfoRequest) (*csi.GetPluginInfoResponse, error) {
+	return &csi.GetPluginInfoResponse{Name: d.name, VendorVersion: "test"}, nil
+}
+
+func (d *Driver) GetPluginCapabilities(context.Context, *csi.GetPluginCapabilitiesRequest) (*csi.GetPluginCapabilitiesResponse, error) {
+	return &csi.GetPluginCapabilitiesResponse{
+		Capabilities: []*csi.PluginCapability{{
+			Type: &csi.PluginCapability_Service_{
+				Service: &csi.PluginCapability_Service{Type: csi.PluginCapability_Service_CONTROLLER_SERVICE},
+			},
+		}},
+	}, nil
+}
+
+func (d *Driver) Probe(context.Context, *csi.ProbeRequest) (*csi.ProbeResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &csi.ProbeResponse{Ready: wrapperspb.Bool(!d.notReady)}, nil
+}
+
+func (d *Driver) GetCSIDriverModificationCapability(context.Context, *modifyrpc.GetCSIDriverModificationCapabilityRequest) (*modifyrpc.GetCSIDriverModificationCapabilityResponse, error) {
+	resp := &modifyrpc.GetCSIDriverModificationCapabilityResponse{Parameters: d.parameters}
+	if d.cooldown > 0 {
+		resp.ModificationCooldown = durationpb.New(d.cooldown)
+	}
+	return resp, nil
+}
+
+func (d *Driver) ModifyVolumeProperties(ctx context.Context, req *modifyrpc.ModifyVolumePropertiesRequest) (*modifyrpc.ModifyVolumePropertiesResponse, error) {
+	if d.latency > 0 {
+		select {
+		case <-time.After(d.latency):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, req)
+
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "volume name is required")
+	}
+	if len(req.GetParameters()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "parameters are required")
+	}
+	current, ok := d.volumes[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "volume %s not found", req.GetName())
+	}
+	if err := d.checkParameters(req.GetParameters()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := d.failures[req.GetName()]; err != nil {
+		return nil, err
+	}
+
+	if !d.async {
+		maps.Copy(current, req.GetParameters())
+		return &modifyrpc.ModifyVolumePropertiesResponse{}, nil
+	}
+
+	// Repeating the request of the modification in progress is idempotent.
+	if opID, ok := d.pending[req.GetName()]; ok && !d.completeOperation(opID) {
+		if !maps.Equal(d.operations[opID].params, req.GetParameters()) {
+			return nil, status.Errorf(codes.Aborted, "modification %s of volume %s is in progress", opID, req.GetName())
+		}
+		return &modifyrpc.ModifyVolumePropertiesResponse{OperationId: opID}, nil
+	}
+	d.lastOpID++
+	opID := "op-" + strconv.Itoa(d.lastOpID)
+	d.operations[opID] = &operation{
+		volumeID: req.GetName(),
+		params:   maps.Clone(req.GetParameters()),
+		done:     time.Now().Add(d.asyncDuration),
+	}
+	d.pending[req.GetName()] = opID
+	return &modifyrpc.ModifyVolumePropertiesResponse{OperationId: opID}, nil
+}
+
+func (d *Driver) GetModificationStatus(_ context.Context, req *modifyrpc.GetModificationStatusRequest) (*modifyrpc.GetModificationStatusResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	op, ok := d.operations[req.GetOperationId()]
+	if !ok || op.volumeID != req.GetName() {
+		return nil, status.Errorf(codes.NotFound, "modification %s of volume %s not found", req.GetOperationId(), req.GetName())
+	}
+	if d.completeOperation(req.GetOperationId()) {
+		return &modifyrpc.GetModificationStatusResponse{State: modifyrpc.GetModificationStatusResponse_COMPLETED}, nil
+	}
+	return &modifyrpc.GetModificationStatusResponse{
+		State:   modifyrpc.GetModificationStatusResponse_IN_PROGRESS,
+		Message: fmt.Sprintf("modifying volume %s", op.volumeID),
+	}, nil
+}
+
+// completeOperation applies the operation to its volume once its duration
+// elapsed and reports whether it completed. d.mu must be held.
+func (d *Driver) completeOperation(opID string) bool {
+	op := d.operations[opID]
+	if time.Now().Before(op.done) {
+		return false
+	}
+	if d.pending[op.volumeID] == opID {
+		if current, ok := d.volumes[op.volumeID]; ok {
+			maps.Copy(current, op.params)
+		}
+		delete(d.pending, op.volumeID)
+	}
+	return true
+}
+
+func (d *Driver) ValidateVolumeModification(_ context.Context, req *modifyrpc.ValidateVolumeModificationRequest) (*modifyrpc.ValidateVolumeModificationResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.volumes[req.GetName()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "volume %s not found", req.GetName())
+	}
+	if err := d.checkParameters(req.GetParameters()); err != nil {
+		return &modifyrpc.ValidateVolumeModificationResponse{Message: err.Error()}, nil
+	}
+	return &modifyrpc.ValidateVolumeModificationResponse{Supported: true}, nil
+}
+
+func (d *Driver) GetVolumeProperties(_ context.Context, req *modifyrpc.GetVolumePropertiesRequest) (*modifyrpc.GetVolumePropertiesResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	params, ok := d.volumes[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "volume %s not found", req.GetName())
+	}
+	return &modifyrpc.GetVolumePropertiesResponse{Parameters: maps.Clone(params)}, nil
+}
+
+// checkParameters checks params against the advertised schema, if any.
+func (d *Driver) checkParameters(params map[string]string) error {
+	if len(d.parameters) == 0 {
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		i := slices.IndexFunc(d.parameters, func(schema *modifyrpc.ParameterSchema) bool { return schema.GetName() == key })
+		if i < 0 {
+			return fmt.Errorf("parameter %q is not supported", key)
+		}
+		if err := checkValue(d.parameters[i], params[key]); err != nil {
+			return fmt.Errorf("parameter %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func checkValue(schema *modifyrpc.ParameterSchema, value string) error {
+	switch schema.GetType() {
+	case modifyrpc.ParameterSchema_INTEGER:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("value %q is not an integer", value)
+		}
+		if schema.MinValue != nil && n < schema.GetMinValue() {
+			return fmt.Errorf("value %d is below the minimum of %d", n, schema.GetMinValue())
+		}
+		if schema.MaxValue != nil && n > schema.GetMaxValue() {
+			return fmt.Errorf("value %d is above the maximum of %d", n, schema.GetMaxValue())
+		}
+	case modifyrpc.ParameterSchema_BOOLEAN:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value %q is not a boolean", value)
+		}
+	}
+	if len(schema.GetAllowedValues()) > 0 && !slices.Contains(schema.GetAllowedValues(), value) {
+		return fmt.Errorf("value %q is not allowed", value)
+	}
+	return nil
+}
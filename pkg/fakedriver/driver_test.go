@@ -0,0 +1,202 @@
+package fakedriver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startDriver serves d on a socket in a temporary directory and connects a
+// client to it. The directory is not created with t.TempDir, whose path may
+// exceed the maximum length of a socket path.
+func startDriver(t *testing.T, d *Driver) csi.Client {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "fakedriver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "csi.sock")
+	if err := d.Start(socket); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.Stop)
+
+	client, err := csi.New(socket, 5*time.Second, metrics.NewCSIMetricsManager(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.CloseConnection)
+	return client
+}
+
+func TestDriver_Identity(t *testing.T) {
+	d := New("fake.csi.k8s.io")
+	client := startDriver(t, d)
+
+	name, err := client.GetDriverName(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "fake.csi.k8s.io" {
+		t.Fatalf("expected driver name fake.csi.k8s.io, got %q", name)
+	}
+
+	d.SetReady(false)
+	if err := client.Probe(context.TODO()); err == nil {
+		t.Fatal("expected probe to fail while the driver is not ready")
+	}
+	d.SetReady(true)
+	if err := client.Probe(context.TODO()); err != nil {
+		t.Fatalf("unexpected probe error: %v", err)
+	}
+}
+
+func TestDriver_Modify(t *testing.T) {
+	minIOPS := int64(3000)
+	d := New("fake.csi.k8s.io", WithParameters(
+		&modifyrpc.ParameterSchema{Name: "iops", Type: modifyrpc.ParameterSchema_INTEGER, MinValue: &minIOPS},
+		&modifyrpc.ParameterSchema{Name: "type", AllowedValues: []string{"gp3", "io2"}},
+	))
+	d.AddVolume("vol-1", map[string]string{"iops": "3000", "type": "gp3"})
+	client := startDriver(t, d)
+
+	capability, err := client.GetModificationCapability(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capability.Parameters) != 2 {
+		t.Fatalf("expected 2 advertised parameters, got %v", capability.Parameters)
+	}
+
+	reqContext := map[string]string{"csi.storage.k8s.io/pvc/name": "data"}
+	secrets := map[string]string{"token": "s3cr3t"}
+	for i := 0; i < 2; i++ {
+		operationID, err := client.Modify(context.TODO(), "vol-1", map[string]string{"iops": "5000"}, reqContext, secrets)
+		if err != nil || operationID != "" {
+			t.Fatalf("expected synchronous modification to succeed, got %q, %v", operationID, err)
+		}
+	}
+	if params, _ := d.Volume("vol-1"); params["iops"] != "5000" || params["type"] != "gp3" {
+		t.Fatalf("unexpected volume parameters %v", params)
+	}
+	requests := d.Requests()
+	if len(requests) != 2 || requests[0].GetSecrets()["token"] != "s3cr3t" ||
+		requests[0].GetContext()["csi.storage.k8s.io/pvc/name"] != "data" {
+		t.Fatalf("expected the requests to be recorded with their context and secrets, got %v", requests)
+	}
+
+	properties, err := client.GetVolumeProperties(context.TODO(), "vol-1")
+	if err != nil || properties["iops"] != "5000" {
+		t.Fatalf("expected volume properties to reflect the modification, got %v, %v", properties, err)
+	}
+
+	if err := client.Validate(context.TODO(), "vol-1", map[string]string{"type": "st1"}, nil, nil); err == nil {
+		t.Fatal("expected validation of a value that is not allowed to fail")
+	}
+
+	tests := []struct {
+		name     string
+		volumeID string
+		params   map[string]string
+		expected codes.Code
+	}{
+		{name: "unknown volume", volumeID: "vol-2", params: map[string]string{"iops": "5000"}, expected: codes.NotFound},
+		{name: "unknown parameter", volumeID: "vol-1", params: map[string]string{"throughput": "500"}, expected: codes.InvalidArgument},
+		{name: "value below minimum", volumeID: "vol-1", params: map[string]string{"iops": "100"}, expected: codes.InvalidArgument},
+		{name: "no parameters", volumeID: "vol-1", expected: codes.InvalidArgument},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.Modify(context.TODO(), tc.volumeID, tc.params, nil, nil)
+			if status.Code(err) != tc.expected {
+				t.Fatalf("expected %s, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestDriver_Failure(t *testing.T) {
+	d := New("fake.csi.k8s.io")
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+	client := startDriver(t, d)
+
+	d.SetFailure("vol-1", status.Error(codes.ResourceExhausted, "request rate exceeded"))
+	_, err := client.Modify(context.TODO(), "vol-1", map[string]string{"iops": "5000"}, nil, nil)
+	var modErr *csi.ModificationError
+	if !errors.As(err, &modErr) || modErr.Code != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted modification error, got %v", err)
+	}
+	if params, _ := d.Volume("vol-1"); params["iops"] != "3000" {
+		t.Fatalf("expected failed modification not to change the volume, got %v", params)
+	}
+
+	d.SetFailure("vol-1", nil)
+	if _, err := client.Modify(context.TODO(), "vol-1", map[string]string{"iops": "5000"}, nil, nil); err != nil {
+		t.Fatalf("unexpected error once the failure is cleared: %v", err)
+	}
+}
+
+func TestDriver_Latency(t *testing.T) {
+	d := New("fake.csi.k8s.io", WithLatency(time.Second))
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+	client := startDriver(t, d)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Modify(ctx, "vol-1", map[string]string{"iops": "5000"}, nil, nil)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestDriver_AsyncModification(t *testing.T) {
+	d := New("fake.csi.k8s.io", WithAsyncModification(200*time.Millisecond))
+	d.AddVolume("vol-1", map[string]string{"iops": "3000"})
+	client := startDriver(t, d)
+
+	params := map[string]string{"iops": "5000"}
+	operationID, err := client.Modify(context.TODO(), "vol-1", params, nil, nil)
+	if err != nil || operationID == "" {
+		t.Fatalf("expected an operation ID, got %q, %v", operationID, err)
+	}
+	repeated, err := client.Modify(context.TODO(), "vol-1", params, nil, nil)
+	if err != nil || repeated != operationID {
+		t.Fatalf("expected repeated request to return operation %q, got %q, %v", operationID, repeated, err)
+	}
+	_, err = client.Modify(context.TODO(), "vol-1", map[string]string{"iops": "6000"}, nil, nil)
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expected Aborted for a different modification in progress, got %v", err)
+	}
+
+	modificationStatus, err := client.GetModificationStatus(context.TODO(), "vol-1", operationID)
+	if err != nil || modificationStatus.State != csi.ModificationInProgress {
+		t.Fatalf("expected modification in progress, got %+v, %v", modificationStatus, err)
+	}
+	if current, _ := d.Volume("vol-1"); current["iops"] != "3000" {
+		t.Fatalf("expected volume not to change before the operation completes, got %v", current)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	modificationStatus, err = client.GetModificationStatus(context.TODO(), "vol-1", operationID)
+	if err != nil || modificationStatus.State != csi.ModificationCompleted {
+		t.Fatalf("expected modification to be completed, got %+v, %v", modificationStatus, err)
+	}
+	if current, _ := d.Volume("vol-1"); current["iops"] != "5000" {
+		t.Fatalf("expected volume to be modified, got %v", current)
+	}
+
+	if _, err := client.GetModificationStatus(context.TODO(), "vol-1", "op-unknown"); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for an unknown operation, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package fakedriver
+
+import (
+	"time"
+
+	modifyrpc "github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+)
+
+// Option configures a Driver.
+type Option func(*Driver)
+
+// WithParameters makes the driver advertise the given parameter schema and
+// reject modifications that do not conform to it. Without a schema, every
+// parameter is accepted as is.
+func WithParameters(parameters ...*modifyrpc.ParameterSchema) Option {
+	return func(d *Driver) {
+		d.parameters = parameters
+	}
+}
+
+// WithCooldown makes the driver advertise the minimum time between two
+// modifications of the same volume. It is not enforced.
+func WithCooldown(cooldown time.Duration) Option {
+	return func(d *Driver) {
+		d.cooldown = cooldown
+	}
+}
+
+// WithLatency delays every ModifyVolumeProperties call.
+func WithLatency(latency time.Duration) Option {
+	return func(d *Driver) {
+		d.latency = latency
+	}
+}
+
+// WithAsyncModification makes ModifyVolumeProperties return an operation ID
+// and apply the modification once duration elapsed, as reported by
+// GetModificationStatus.
+func WithAsyncModification(duration time.Duration) Option {
+	return func(d *Driver) {
+		d.async = true
+		d.asyncDuration = duration
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/typed/volumemodifier/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeVolumeModifications implements VolumeModificationInterface
+type fakeVolumeModifications struct {
+	*gentype.FakeClientWithList[*v1alpha1.VolumeModification, *v1alpha1.VolumeModificationList]
+	Fake *FakeVolumemodifierV1alpha1
+}
+
+func newFakeVolumeModifications(fake *FakeVolumemodifierV1alpha1, namespace string) volumemodifierv1alpha1.VolumeModificationInterface {
+	return &fakeVolumeModifications{
+		gentype.NewFakeClientWithList[*v1alpha1.VolumeModification, *v1alpha1.VolumeModificationList](
+			fake.Fake,
+			namespace,
+			v1alpha1.SchemeGroupVersion.WithResource("volumemodifications"),
+			v1alpha1.SchemeGroupVersion.WithKind("VolumeModification"),
+			func() *v1alpha1.VolumeModification { return &v1alpha1.VolumeModification{} },
+			func() *v1alpha1.VolumeModificationList { return &v1alpha1.VolumeModificationList{} },
+			func(dst, src *v1alpha1.VolumeModificationList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.VolumeModificationList) []*v1alpha1.VolumeModification {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.VolumeModificationList, items []*v1alpha1.VolumeModification) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/typed/volumemodifier/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeVolumeModificationPlans implements VolumeModificationPlanInterface
+type fakeVolumeModificationPlans struct {
+	*gentype.FakeClientWithList[*v1alpha1.VolumeModificationPlan, *v1alpha1.VolumeModificationPlanList]
+	Fake *FakeVolumemodifierV1alpha1
+}
+
+func newFakeVolumeModificationPlans(fake *FakeVolumemodifierV1alpha1) volumemodifierv1alpha1.VolumeModificationPlanInterface {
+	return &fakeVolumeModificationPlans{
+		gentype.NewFakeClientWithList[*v1alpha1.VolumeModificationPlan, *v1alpha1.VolumeModificationPlanList](
+			fake.Fake,
+			"",
+			v1alpha1.SchemeGroupVersion.WithResource("volumemodificationplans"),
+			v1alpha1.SchemeGroupVersion.WithKind("VolumeModificationPlan"),
+			func() *v1alpha1.VolumeModificationPlan { return &v1alpha1.VolumeModificationPlan{} },
+			func() *v1alpha1.VolumeModificationPlanList { return &v1alpha1.VolumeModificationPlanList{} },
+			func(dst, src *v1alpha1.VolumeModificationPlanList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.VolumeModificationPlanList) []*v1alpha1.VolumeModificationPlan {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.VolumeModificationPlanList, items []*v1alpha1.VolumeModificationPlan) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
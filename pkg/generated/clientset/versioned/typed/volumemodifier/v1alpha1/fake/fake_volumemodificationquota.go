@@ -0,0 +1,52 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/typed/volumemodifier/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeVolumeModificationQuotas implements VolumeModificationQuotaInterface
+type fakeVolumeModificationQuotas struct {
+	*gentype.FakeClientWithList[*v1alpha1.VolumeModificationQuota, *v1alpha1.VolumeModificationQuotaList]
+	Fake *FakeVolumemodifierV1alpha1
+}
+
+func newFakeVolumeModificationQuotas(fake *FakeVolumemodifierV1alpha1, namespace string) volumemodifierv1alpha1.VolumeModificationQuotaInterface {
+	return &fakeVolumeModificationQuotas{
+		gentype.NewFakeClientWithList[*v1alpha1.VolumeModificationQuota, *v1alpha1.VolumeModificationQuotaList](
+			fake.Fake,
+			namespace,
+			v1alpha1.SchemeGroupVersion.WithResource("volumemodificationquotas"),
+			v1alpha1.SchemeGroupVersion.WithKind("VolumeModificationQuota"),
+			func() *v1alpha1.VolumeModificationQuota { return &v1alpha1.VolumeModificationQuota{} },
+			func() *v1alpha1.VolumeModificationQuotaList { return &v1alpha1.VolumeModificationQuotaList{} },
+			func(dst, src *v1alpha1.VolumeModificationQuotaList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.VolumeModificationQuotaList) []*v1alpha1.VolumeModificationQuota {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.VolumeModificationQuotaList, items []*v1alpha1.VolumeModificationQuota) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/typed/volumemodifier/v1alpha1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeVolumemodifierV1alpha1 struct {
+	*testing.Fake
+}
+
+func (c *FakeVolumemodifierV1alpha1) VolumeModifications(namespace string) v1alpha1.VolumeModificationInterface {
+	return newFakeVolumeModifications(c, namespace)
+}
+
+func (c *FakeVolumemodifierV1alpha1) VolumeModificationPlans() v1alpha1.VolumeModificationPlanInterface {
+	return newFakeVolumeModificationPlans(c)
+}
+
+func (c *FakeVolumemodifierV1alpha1) VolumeModificationQuotas(namespace string) v1alpha1.VolumeModificationQuotaInterface {
+	return newFakeVolumeModificationQuotas(c, namespace)
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeVolumemodifierV1alpha1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
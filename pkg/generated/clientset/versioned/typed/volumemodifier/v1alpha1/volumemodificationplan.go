@@ -0,0 +1,74 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	scheme "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// VolumeModificationPlansGetter has a method to return a VolumeModificationPlanInterface.
+// A group's client should implement this interface.
+type VolumeModificationPlansGetter interface {
+	VolumeModificationPlans() VolumeModificationPlanInterface
+}
+
+// VolumeModificationPlanInterface has methods to work with VolumeModificationPlan resources.
+type VolumeModificationPlanInterface interface {
+	Create(ctx context.Context, volumeModificationPlan *volumemodifierv1alpha1.VolumeModificationPlan, opts v1.CreateOptions) (*volumemodifierv1alpha1.VolumeModificationPlan, error)
+	Update(ctx context.Context, volumeModificationPlan *volumemodifierv1alpha1.VolumeModificationPlan, opts v1.UpdateOptions) (*volumemodifierv1alpha1.VolumeModificationPlan, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, volumeModificationPlan *volumemodifierv1alpha1.VolumeModificationPlan, opts v1.UpdateOptions) (*volumemodifierv1alpha1.VolumeModificationPlan, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*volumemodifierv1alpha1.VolumeModificationPlan, error)
+	List(ctx context.Context, opts v1.ListOptions) (*volumemodifierv1alpha1.VolumeModificationPlanList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *volumemodifierv1alpha1.VolumeModificationPlan, err error)
+	VolumeModificationPlanExpansion
+}
+
+// volumeModificationPlans implements VolumeModificationPlanInterface
+type volumeModificationPlans struct {
+	*gentype.ClientWithList[*volumemodifierv1alpha1.VolumeModificationPlan, *volumemodifierv1alpha1.VolumeModificationPlanList]
+}
+
+// newVolumeModificationPlans returns a VolumeModificationPlans
+func newVolumeModificationPlans(c *VolumemodifierV1alpha1Client) *volumeModificationPlans {
+	return &volumeModificationPlans{
+		gentype.NewClientWithList[*volumemodifierv1alpha1.VolumeModificationPlan, *volumemodifierv1alpha1.VolumeModificationPlanList](
+			"volumemodificationplans",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *volumemodifierv1alpha1.VolumeModificationPlan {
+				return &volumemodifierv1alpha1.VolumeModificationPlan{}
+			},
+			func() *volumemodifierv1alpha1.VolumeModificationPlanList {
+				return &volumemodifierv1alpha1.VolumeModificationPlanList{}
+			},
+		),
+	}
+}
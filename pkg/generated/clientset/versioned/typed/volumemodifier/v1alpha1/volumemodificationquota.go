@@ -0,0 +1,74 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	scheme "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// VolumeModificationQuotasGetter has a method to return a VolumeModificationQuotaInterface.
+// A group's client should implement this interface.
+type VolumeModificationQuotasGetter interface {
+	VolumeModificationQuotas(namespace string) VolumeModificationQuotaInterface
+}
+
+// VolumeModificationQuotaInterface has methods to work with VolumeModificationQuota resources.
+type VolumeModificationQuotaInterface interface {
+	Create(ctx context.Context, volumeModificationQuota *volumemodifierv1alpha1.VolumeModificationQuota, opts v1.CreateOptions) (*volumemodifierv1alpha1.VolumeModificationQuota, error)
+	Update(ctx context.Context, volumeModificationQuota *volumemodifierv1alpha1.VolumeModificationQuota, opts v1.UpdateOptions) (*volumemodifierv1alpha1.VolumeModificationQuota, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, volumeModificationQuota *volumemodifierv1alpha1.VolumeModificationQuota, opts v1.UpdateOptions) (*volumemodifierv1alpha1.VolumeModificationQuota, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*volumemodifierv1alpha1.VolumeModificationQuota, error)
+	List(ctx context.Context, opts v1.ListOptions) (*volumemodifierv1alpha1.VolumeModificationQuotaList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *volumemodifierv1alpha1.VolumeModificationQuota, err error)
+	VolumeModificationQuotaExpansion
+}
+
+// volumeModificationQuotas implements VolumeModificationQuotaInterface
+type volumeModificationQuotas struct {
+	*gentype.ClientWithList[*volumemodifierv1alpha1.VolumeModificationQuota, *volumemodifierv1alpha1.VolumeModificationQuotaList]
+}
+
+// newVolumeModificationQuotas returns a VolumeModificationQuotas
+func newVolumeModificationQuotas(c *VolumemodifierV1alpha1Client, namespace string) *volumeModificationQuotas {
+	return &volumeModificationQuotas{
+		gentype.NewClientWithList[*volumemodifierv1alpha1.VolumeModificationQuota, *volumemodifierv1alpha1.VolumeModificationQuotaList](
+			"volumemodificationquotas",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			namespace,
+			func() *volumemodifierv1alpha1.VolumeModificationQuota {
+				return &volumemodifierv1alpha1.VolumeModificationQuota{}
+			},
+			func() *volumemodifierv1alpha1.VolumeModificationQuotaList {
+				return &volumemodifierv1alpha1.VolumeModificationQuotaList{}
+			},
+		),
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	internalinterfaces "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// VolumeModifications returns a VolumeModificationInformer.
+	VolumeModifications() VolumeModificationInformer
+	// VolumeModificationPlans returns a VolumeModificationPlanInformer.
+	VolumeModificationPlans() VolumeModificationPlanInformer
+	// VolumeModificationQuotas returns a VolumeModificationQuotaInformer.
+	VolumeModificationQuotas() VolumeModificationQuotaInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// VolumeModifications returns a VolumeModificationInformer.
+func (v *version) VolumeModifications() VolumeModificationInformer {
+	return &volumeModificationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// VolumeModificationPlans returns a VolumeModificationPlanInformer.
+func (v *version) VolumeModificationPlans() VolumeModificationPlanInformer {
+	return &volumeModificationPlanInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// VolumeModificationQuotas returns a VolumeModificationQuotaInformer.
+func (v *version) VolumeModificationQuotas() VolumeModificationQuotaInformer {
+	return &volumeModificationQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+	time "time"
+
+	apisvolumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	versioned "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions/internalinterfaces"
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VolumeModificationPlanInformer provides access to a shared informer and lister for
+// VolumeModificationPlans.
+type VolumeModificationPlanInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() volumemodifierv1alpha1.VolumeModificationPlanLister
+}
+
+type volumeModificationPlanInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewVolumeModificationPlanInformer constructs a new informer for VolumeModificationPlan type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewVolumeModificationPlanInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredVolumeModificationPlanInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredVolumeModificationPlanInformer constructs a new informer for VolumeModificationPlan type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredVolumeModificationPlanInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationPlans().List(context.Background(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationPlans().Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationPlans().List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationPlans().Watch(ctx, options)
+			},
+		}, client),
+		&apisvolumemodifierv1alpha1.VolumeModificationPlan{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *volumeModificationPlanInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredVolumeModificationPlanInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *volumeModificationPlanInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&apisvolumemodifierv1alpha1.VolumeModificationPlan{}, f.defaultInformer)
+}
+
+func (f *volumeModificationPlanInformer) Lister() volumemodifierv1alpha1.VolumeModificationPlanLister {
+	return volumemodifierv1alpha1.NewVolumeModificationPlanLister(f.Informer().GetIndexer())
+}
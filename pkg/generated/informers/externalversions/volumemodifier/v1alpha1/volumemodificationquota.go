@@ -0,0 +1,102 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	context "context"
+	time "time"
+
+	apisvolumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	versioned "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/informers/externalversions/internalinterfaces"
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/generated/listers/volumemodifier/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VolumeModificationQuotaInformer provides access to a shared informer and lister for
+// VolumeModificationQuotas.
+type VolumeModificationQuotaInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() volumemodifierv1alpha1.VolumeModificationQuotaLister
+}
+
+type volumeModificationQuotaInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewVolumeModificationQuotaInformer constructs a new informer for VolumeModificationQuota type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewVolumeModificationQuotaInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredVolumeModificationQuotaInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredVolumeModificationQuotaInformer constructs a new informer for VolumeModificationQuota type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredVolumeModificationQuotaInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationQuotas(namespace).List(context.Background(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationQuotas(namespace).Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationQuotas(namespace).List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VolumemodifierV1alpha1().VolumeModificationQuotas(namespace).Watch(ctx, options)
+			},
+		}, client),
+		&apisvolumemodifierv1alpha1.VolumeModificationQuota{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *volumeModificationQuotaInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredVolumeModificationQuotaInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *volumeModificationQuotaInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&apisvolumemodifierv1alpha1.VolumeModificationQuota{}, f.defaultInformer)
+}
+
+func (f *volumeModificationQuotaInformer) Lister() volumemodifierv1alpha1.VolumeModificationQuotaLister {
+	return volumemodifierv1alpha1.NewVolumeModificationQuotaLister(f.Informer().GetIndexer())
+}
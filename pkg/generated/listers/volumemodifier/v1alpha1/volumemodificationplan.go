@@ -0,0 +1,48 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VolumeModificationPlanLister helps list VolumeModificationPlans.
+// All objects returned here must be treated as read-only.
+type VolumeModificationPlanLister interface {
+	// List lists all VolumeModificationPlans in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*volumemodifierv1alpha1.VolumeModificationPlan, err error)
+	// Get retrieves the VolumeModificationPlan from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*volumemodifierv1alpha1.VolumeModificationPlan, error)
+	VolumeModificationPlanListerExpansion
+}
+
+// volumeModificationPlanLister implements the VolumeModificationPlanLister interface.
+type volumeModificationPlanLister struct {
+	listers.ResourceIndexer[*volumemodifierv1alpha1.VolumeModificationPlan]
+}
+
+// NewVolumeModificationPlanLister returns a new VolumeModificationPlanLister.
+func NewVolumeModificationPlanLister(indexer cache.Indexer) VolumeModificationPlanLister {
+	return &volumeModificationPlanLister{listers.New[*volumemodifierv1alpha1.VolumeModificationPlan](indexer, volumemodifierv1alpha1.Resource("volumemodificationplan"))}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	volumemodifierv1alpha1 "github.com/awslabs/volume-modifier-for-k8s/pkg/apis/volumemodifier/v1alpha1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VolumeModificationQuotaLister helps list VolumeModificationQuotas.
+// All objects returned here must be treated as read-only.
+type VolumeModificationQuotaLister interface {
+	// List lists all VolumeModificationQuotas in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*volumemodifierv1alpha1.VolumeModificationQuota, err error)
+	// VolumeModificationQuotas returns an object that can list and get VolumeModificationQuotas.
+	VolumeModificationQuotas(namespace string) VolumeModificationQuotaNamespaceLister
+	VolumeModificationQuotaListerExpansion
+}
+
+// volumeModificationQuotaLister implements the VolumeModificationQuotaLister interface.
+type volumeModificationQuotaLister struct {
+	listers.ResourceIndexer[*volumemodifierv1alpha1.VolumeModificationQuota]
+}
+
+// NewVolumeModificationQuotaLister returns a new VolumeModificationQuotaLister.
+func NewVolumeModificationQuotaLister(indexer cache.Indexer) VolumeModificationQuotaLister {
+	return &volumeModificationQuotaLister{listers.New[*volumemodifierv1alpha1.VolumeModificationQuota](indexer, volumemodifierv1alpha1.Resource("volumemodificationquota"))}
+}
+
+// VolumeModificationQuotas returns an object that can list and get VolumeModificationQuotas.
+func (s *volumeModificationQuotaLister) VolumeModificationQuotas(namespace string) VolumeModificationQuotaNamespaceLister {
+	return volumeModificationQuotaNamespaceLister{listers.NewNamespaced[*volumemodifierv1alpha1.VolumeModificationQuota](s.ResourceIndexer, namespace)}
+}
+
+// VolumeModificationQuotaNamespaceLister helps list and get VolumeModificationQuotas.
+// All objects returned here must be treated as read-only.
+type VolumeModificationQuotaNamespaceLister interface {
+	// List lists all VolumeModificationQuotas in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*volumemodifierv1alpha1.VolumeModificationQuota, err error)
+	// Get retrieves the VolumeModificationQuota from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*volumemodifierv1alpha1.VolumeModificationQuota, error)
+	VolumeModificationQuotaNamespaceListerExpansion
+}
+
+// volumeModificationQuotaNamespaceLister implements the VolumeModificationQuotaNamespaceLister
+// interface.
+type volumeModificationQuotaNamespaceLister struct {
+	listers.ResourceIndexer[*volumemodifierv1alpha1.VolumeModificationQuota]
+}
@@ -37,31 +37,84 @@ func (c *csiModifier) Name() string {
 	return c.name
 }
 
-func (c *csiModifier) Modify(pv *v1.PersistentVolume, params, reqContext map[string]string) error {
-	klog.V(5).InfoS("Received modify request", "pv", pv, "params", params)
+func (c *csiModifier) Modify(pv *v1.PersistentVolume, params, reqContext, secrets map[string]string) (string, error) {
+	// Only the number of secrets is logged, never their values.
+	klog.V(5).InfoS("Received modify request", "pv", pv, "params", params, "secrets", len(secrets))
 
-	var (
-		volumeID string
-	)
-
-	if pv.Spec.CSI != nil {
-		volumeID = pv.Spec.CSI.VolumeHandle
-	} else {
-		translator := csitrans.New()
-		if translator.IsMigratedCSIDriverByName(c.name) {
-			csiPV, err := translator.TranslateInTreePVToCSI(klog.Background(), pv)
-			if err != nil {
-				return fmt.Errorf("failed to translate persistent volume: %w", err)
-			}
-			volumeID = csiPV.Spec.CSI.VolumeHandle
-		} else {
-			return fmt.Errorf("volume %v is not migrated to CSI", pv.Name)
-		}
+	volumeID, err := c.volumeID(pv)
+	if err != nil {
+		return "", err
 	}
 
 	klog.InfoS("Calling modify volume for volume", "volumeID", volumeID)
 
 	ctx, cancel := context.WithTimeout(context.TODO(), c.timeout)
 	defer cancel()
-	return c.client.Modify(ctx, volumeID, params, reqContext)
+	return c.client.Modify(ctx, volumeID, params, reqContext, secrets)
+}
+
+func (c *csiModifier) Validate(pv *v1.PersistentVolume, params, reqContext, secrets map[string]string) error {
+	// Only the number of secrets is logged, never their values.
+	klog.V(5).InfoS("Received validate request", "pv", pv, "params", params, "secrets", len(secrets))
+
+	volumeID, err := c.volumeID(pv)
+	if err != nil {
+		return err
+	}
+
+	klog.InfoS("Calling validate modification for volume", "volumeID", volumeID)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), c.timeout)
+	defer cancel()
+	return c.client.Validate(ctx, volumeID, params, reqContext, secrets)
+}
+
+func (c *csiModifier) GetModificationStatus(pv *v1.PersistentVolume, operationID string) (*csi.ModificationStatus, error) {
+	volumeID, err := c.volumeID(pv)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(4).InfoS("Getting modification status for volume", "volumeID", volumeID, "operationID", operationID)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), c.timeout)
+	defer cancel()
+	return c.client.GetModificationStatus(ctx, volumeID, operationID)
+}
+
+func (c *csiModifier) GetVolumeProperties(pv *v1.PersistentVolume) (map[string]string, error) {
+	volumeID, err := c.volumeID(pv)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.V(4).InfoS("Getting properties of volume", "volumeID", volumeID)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), c.timeout)
+	defer cancel()
+	return c.client.GetVolumeProperties(ctx, volumeID)
+}
+
+func (c *csiModifier) GetModificationCapability() (*csi.ModificationCapability, error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), c.timeout)
+	defer cancel()
+	return c.client.GetModificationCapability(ctx)
+}
+
+// volumeID returns the CSI volume handle of pv, translating in-tree
+// volumes of migrated drivers.
+func (c *csiModifier) volumeID(pv *v1.PersistentVolume) (string, error) {
+	if pv.Spec.CSI != nil {
+		return pv.Spec.CSI.VolumeHandle, nil
+	}
+
+	translator := csitrans.New()
+	if !translator.IsMigratedCSIDriverByName(c.name) {
+		return "", fmt.Errorf("volume %v is not migrated to CSI", pv.Name)
+	}
+	csiPV, err := translator.TranslateInTreePVToCSI(klog.Background(), pv)
+	if err != nil {
+		return "", fmt.Errorf("failed to translate persistent volume: %w", err)
+	}
+	return csiPV.Spec.CSI.VolumeHandle, nil
 }
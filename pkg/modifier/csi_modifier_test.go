@@ -1,6 +1,9 @@
 package modifier
 
 import (
+	"bytes"
+	"flag"
+	"strings"
 	"testing"
 
 	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
@@ -10,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
 )
 
 func TestNewModifier(t *testing.T) {
@@ -96,7 +100,7 @@ func TestModify(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			err = modifier.Modify(pv, tc.params, tc.reqContext)
+			_, err = modifier.Modify(pv, tc.params, tc.reqContext, nil)
 			if err != nil {
 				if !tc.clientReturnsError {
 					t.Fatal(err)
@@ -115,6 +119,41 @@ func TestModify(t *testing.T) {
 	}
 }
 
+func TestModify_Secrets(t *testing.T) {
+	var logs bytes.Buffer
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	flags.Set("v", "5")
+	flags.Set("logtostderr", "false")
+	klog.SetOutput(&logs)
+	t.Cleanup(func() {
+		flags.Set("v", "0")
+		flags.Set("logtostderr", "true")
+	})
+
+	client := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+	modifier, err := NewFromClient("ebs.csi.aws.com", client, getFakeKubernetesClient(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pv := newFakeCSIPV("test", "ebs.csi.aws.com", "vol-1234355446a2")
+	secrets := map[string]string{"key": "s3cr3t-value"}
+
+	if _, err := modifier.Modify(pv, map[string]string{"foo": "bar"}, map[string]string{}, secrets); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(client.GetSecrets(), secrets); diff != "" {
+		t.Fatalf("unexpected secrets: diff = %v", diff)
+	}
+	klog.Flush()
+	if !strings.Contains(logs.String(), "Received modify request") {
+		t.Fatalf("expected modify request to be logged, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "s3cr3t-value") {
+		t.Fatalf("expected secret values not to be logged, got %q", logs.String())
+	}
+}
+
 func TestModify_NonCSINonMigratedPV(t *testing.T) {
 	client := csi.NewFakeClient("unknown.driver.io", true, false)
 	k8sClient := getFakeKubernetesClient()
@@ -131,12 +170,63 @@ func TestModify_NonCSINonMigratedPV(t *testing.T) {
 			},
 		},
 	}
-	err = modifier.Modify(pv, map[string]string{"foo": "bar"}, map[string]string{})
+	_, err = modifier.Modify(pv, map[string]string{"foo": "bar"}, map[string]string{}, nil)
 	if err == nil {
 		t.Fatal("expected error for non-CSI non-migrated PV, got nil")
 	}
 }
 
+func TestModify_AsyncOperation(t *testing.T) {
+	client := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+	client.SetAsyncModification("op-1", csi.ModificationStatus{State: csi.ModificationCompleted})
+	modifier, err := NewFromClient("ebs.csi.aws.com", client, getFakeKubernetesClient(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pv := newFakeCSIPV("test", "ebs.csi.aws.com", "vol-1234355446a2")
+
+	operationID, err := modifier.Modify(pv, map[string]string{"foo": "bar"}, map[string]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operationID != "op-1" {
+		t.Fatalf("unexpected operation ID: got %q, expected %q", operationID, "op-1")
+	}
+
+	status, err := modifier.GetModificationStatus(pv, operationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != csi.ModificationCompleted {
+		t.Fatalf("unexpected operation state: %v", status.State)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	client := csi.NewFakeClient("ebs.csi.aws.com", true, false)
+	modifier, err := NewFromClient("ebs.csi.aws.com", client, getFakeKubernetesClient(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	volumeID := "vol-1234355446a2"
+	pv := newFakeCSIPV("test", "ebs.csi.aws.com", volumeID)
+
+	if err := modifier.Validate(pv, map[string]string{"foo": "bar"}, map[string]string{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if client.GetVolumeName() != volumeID {
+		t.Fatalf("unexpected volume ID: got %s, expected %s", client.GetVolumeName(), volumeID)
+	}
+
+	client.SetValidationError("foo is not supported")
+	if err := modifier.Validate(pv, map[string]string{"foo": "bar"}, map[string]string{}, nil); err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+	if client.GetModifyCallCount() != 0 {
+		t.Fatalf("expected validation not to modify the volume, got %d modify calls", client.GetModifyCallCount())
+	}
+}
+
 func newFakeInTreePV(name, volumeID string) *v1.PersistentVolume {
 	pv := &v1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1,9 +1,28 @@
 package modifier
 
-import v1 "k8s.io/api/core/v1"
+import (
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	v1 "k8s.io/api/core/v1"
+)
 
 type Modifier interface {
 	Name() string
 
-	Modify(*v1.PersistentVolume, map[string]string, map[string]string) error
+	// Modify returns a non-empty operation ID when the modification is
+	// still in progress on the driver side. The last argument holds the
+	// secrets to pass to the driver, if any.
+	Modify(*v1.PersistentVolume, map[string]string, map[string]string, map[string]string) (string, error)
+
+	GetModificationStatus(*v1.PersistentVolume, string) (*csi.ModificationStatus, error)
+
+	GetModificationCapability() (*csi.ModificationCapability, error)
+
+	// Validate checks whether the driver would accept the modification
+	// without changing the volume. The last argument holds the secrets to
+	// pass to the driver, if any, as for Modify.
+	Validate(*v1.PersistentVolume, map[string]string, map[string]string, map[string]string) error
+
+	// GetVolumeProperties returns the attributes the driver reports for
+	// the volume.
+	GetVolumeProperties(*v1.PersistentVolume) (map[string]string, error)
 }
@@ -24,6 +24,9 @@ const _ = grpc.SupportPackageIsVersion7
 type ModifyClient interface {
 	GetCSIDriverModificationCapability(ctx context.Context, in *GetCSIDriverModificationCapabilityRequest, opts ...grpc.CallOption) (*GetCSIDriverModificationCapabilityResponse, error)
 	ModifyVolumeProperties(ctx context.Context, in *ModifyVolumePropertiesRequest, opts ...grpc.CallOption) (*ModifyVolumePropertiesResponse, error)
+	GetModificationStatus(ctx context.Context, in *GetModificationStatusRequest, opts ...grpc.CallOption) (*GetModificationStatusResponse, error)
+	ValidateVolumeModification(ctx context.Context, in *ValidateVolumeModificationRequest, opts ...grpc.CallOption) (*ValidateVolumeModificationResponse, error)
+	GetVolumeProperties(ctx context.Context, in *GetVolumePropertiesRequest, opts ...grpc.CallOption) (*GetVolumePropertiesResponse, error)
 }
 
 type modifyClient struct {
@@ -52,12 +55,42 @@ func (c *modifyClient) ModifyVolumeProperties(ctx context.Context, in *ModifyVol
 	return out, nil
 }
 
+func (c *modifyClient) GetModificationStatus(ctx context.Context, in *GetModificationStatusRequest, opts ...grpc.CallOption) (*GetModificationStatusResponse, error) {
+	out := new(GetModificationStatusResponse)
+	err := c.cc.Invoke(ctx, "/modify.v1.Modify/GetModificationStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *modifyClient) ValidateVolumeModification(ctx context.Context, in *ValidateVolumeModificationRequest, opts ...grpc.CallOption) (*ValidateVolumeModificationResponse, error) {
+	out := new(ValidateVolumeModificationResponse)
+	err := c.cc.Invoke(ctx, "/modify.v1.Modify/ValidateVolumeModification", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *modifyClient) GetVolumeProperties(ctx context.Context, in *GetVolumePropertiesRequest, opts ...grpc.CallOption) (*GetVolumePropertiesResponse, error) {
+	out := new(GetVolumePropertiesResponse)
+	err := c.cc.Invoke(ctx, "/modify.v1.Modify/GetVolumeProperties", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ModifyServer is the server API for Modify service.
 // All implementations must embed UnimplementedModifyServer
 // for forward compatibility
 type ModifyServer interface {
 	GetCSIDriverModificationCapability(context.Context, *GetCSIDriverModificationCapabilityRequest) (*GetCSIDriverModificationCapabilityResponse, error)
 	ModifyVolumeProperties(context.Context, *ModifyVolumePropertiesRequest) (*ModifyVolumePropertiesResponse, error)
+	GetModificationStatus(context.Context, *GetModificationStatusRequest) (*GetModificationStatusResponse, error)
+	ValidateVolumeModification(context.Context, *ValidateVolumeModificationRequest) (*ValidateVolumeModificationResponse, error)
+	GetVolumeProperties(context.Context, *GetVolumePropertiesRequest) (*GetVolumePropertiesResponse, error)
 	mustEmbedUnimplementedModifyServer()
 }
 
@@ -71,6 +104,15 @@ func (UnimplementedModifyServer) GetCSIDriverModificationCapability(context.Cont
 func (UnimplementedModifyServer) ModifyVolumeProperties(context.Context, *ModifyVolumePropertiesRequest) (*ModifyVolumePropertiesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ModifyVolumeProperties not implemented")
 }
+func (UnimplementedModifyServer) GetModificationStatus(context.Context, *GetModificationStatusRequest) (*GetModificationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModificationStatus not implemented")
+}
+func (UnimplementedModifyServer) ValidateVolumeModification(context.Context, *ValidateVolumeModificationRequest) (*ValidateVolumeModificationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateVolumeModification not implemented")
+}
+func (UnimplementedModifyServer) GetVolumeProperties(context.Context, *GetVolumePropertiesRequest) (*GetVolumePropertiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVolumeProperties not implemented")
+}
 func (UnimplementedModifyServer) mustEmbedUnimplementedModifyServer() {}
 
 // UnsafeModifyServer may be embedded to opt out of forward compatibility for this service.
@@ -120,6 +162,60 @@ func _Modify_ModifyVolumeProperties_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Modify_GetModificationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetModificationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModifyServer).GetModificationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/modify.v1.Modify/GetModificationStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModifyServer).GetModificationStatus(ctx, req.(*GetModificationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Modify_ValidateVolumeModification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateVolumeModificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModifyServer).ValidateVolumeModification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/modify.v1.Modify/ValidateVolumeModification",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModifyServer).ValidateVolumeModification(ctx, req.(*ValidateVolumeModificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Modify_GetVolumeProperties_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVolumePropertiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModifyServer).GetVolumeProperties(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/modify.v1.Modify/GetVolumeProperties",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModifyServer).GetVolumeProperties(ctx, req.(*GetVolumePropertiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Modify_ServiceDesc is the grpc.ServiceDesc for Modify service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,6 +231,18 @@ var Modify_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ModifyVolumeProperties",
 			Handler:    _Modify_ModifyVolumeProperties_Handler,
 		},
+		{
+			MethodName: "GetModificationStatus",
+			Handler:    _Modify_GetModificationStatus_Handler,
+		},
+		{
+			MethodName: "ValidateVolumeModification",
+			Handler:    _Modify_ValidateVolumeModification_Handler,
+		},
+		{
+			MethodName: "GetVolumeProperties",
+			Handler:    _Modify_GetVolumeProperties_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "modify.proto",
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// A Friday.
+	from := time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		expr     string
+		expected time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2026, 10, 16, 18, 31, 0, 0, time.UTC)},
+		{"daily", "0 2 * * *", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"later today", "45 18 * * *", time.Date(2026, 10, 16, 18, 45, 0, 0, time.UTC)},
+		{"step", "*/20 * * * *", time.Date(2026, 10, 16, 18, 40, 0, 0, time.UTC)},
+		{"weekday name", "0 2 * * SAT", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 2 * * 7", time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)},
+		{"weekday range", "0 2 * * MON-WED", time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)},
+		{"month name", "0 0 1 JAN *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"day of month or week", "0 0 1 * MON", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"list", "0 1,23 * * *", time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.Next(from); !got.Equal(tc.expected) {
+				t.Errorf("Next() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext_Never(t *testing.T) {
+	schedule, err := ParseCronSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero time", got)
+	}
+}
+
+func TestParseCronSchedule_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * * FOO",
+	} {
+		if _, err := ParseCronSchedule(expr); err == nil {
+			t.Errorf("expected %q to be invalid", expr)
+		}
+	}
+}
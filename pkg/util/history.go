@@ -0,0 +1,92 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// ModificationHistoryAnnotationPattern is the PV annotation holding the
+// modification history of the volume for a driver, as a JSON array of
+// ModificationRecord ordered from oldest to newest.
+const ModificationHistoryAnnotationPattern = "%s/modification-history"
+
+// Results of a ModificationRecord.
+const (
+	ModificationResultSucceeded = "succeeded"
+	ModificationResultFailed    = "failed"
+)
+
+// ModificationRecord is an entry of the modification history of a volume.
+type ModificationRecord struct {
+	// Time is when the modification completed or failed.
+	Time time.Time `json:"time"`
+	// Before holds the values the modified parameters had before, if any.
+	Before map[string]string `json:"before,omitempty"`
+	// After holds the requested values of the modified parameters.
+	After map[string]string `json:"after"`
+	// PVCResourceVersion is the resourceVersion of the PVC that requested
+	// the modification, to correlate it with the audit log.
+	PVCResourceVersion string `json:"pvcResourceVersion,omitempty"`
+	// Result is ModificationResultSucceeded or ModificationResultFailed.
+	Result string `json:"result"`
+	// Message explains a failure.
+	Message string `json:"message,omitempty"`
+	// Count is the number of consecutive identical failures the record
+	// stands for, when more than one. Time is then the last of them.
+	Count int `json:"count,omitempty"`
+}
+
+// ParseModificationHistory decodes the value of the
+// ModificationHistoryAnnotationPattern annotation.
+func ParseModificationHistory(value string) ([]ModificationRecord, error) {
+	var history []ModificationRecord
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil, fmt.Errorf("invalid modification history: %w", err)
+	}
+	return history, nil
+}
+
+// GetModificationHistory returns the modification history recorded on the PV
+// by the modifier of the driver, from oldest to newest. It returns an empty
+// history if none was recorded.
+func GetModificationHistory(pv *v1.PersistentVolume, driverName string) ([]ModificationRecord, error) {
+	value, ok := pv.Annotations[fmt.Sprintf(ModificationHistoryAnnotationPattern, driverName)]
+	if !ok {
+		return nil, nil
+	}
+	return ParseModificationHistory(value)
+}
+
+// AppendModificationHistory appends the record to the history, dropping the
+// oldest records beyond limit, and returns the encoded history. A failure
+// identical to the last record, as when a modification is retried, is
+// counted in that record instead of being appended.
+func AppendModificationHistory(history []ModificationRecord, record ModificationRecord, limit int) (string, error) {
+	if n := len(history); n > 0 && sameFailure(history[n-1], record) {
+		last := history[n-1]
+		record.Count = max(last.Count, 1) + 1
+		history = append(history[:n-1:n-1], record)
+	} else {
+		history = append(history, record)
+	}
+	if limit > 0 && len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sameFailure returns true if both records are failures of the same
+// modification with the same message. The resourceVersion of the PVC is not
+// compared, as recording the failure on the PVC changes it.
+func sameFailure(a, b ModificationRecord) bool {
+	return a.Result == ModificationResultFailed && b.Result == ModificationResultFailed &&
+		a.Message == b.Message && maps.Equal(a.Before, b.Before) && maps.Equal(a.After, b.After)
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppendModificationHistory(t *testing.T) {
+	var history []ModificationRecord
+	for i := 0; i < 5; i++ {
+		value, err := AppendModificationHistory(history, ModificationRecord{
+			Time:   time.Date(2026, 10, 16, 12, i, 0, 0, time.UTC),
+			After:  map[string]string{"iops": fmt.Sprint(3000 + i)},
+			Result: ModificationResultSucceeded,
+		}, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if history, err = ParseModificationHistory(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(history) != 3 {
+		t.Fatalf("expected history to be bounded to 3 records, got %d", len(history))
+	}
+	for i, record := range history {
+		if expected := fmt.Sprint(3002 + i); record.After["iops"] != expected {
+			t.Errorf("expected record %d to set iops to %s, got %v", i, expected, record.After)
+		}
+	}
+}
+
+func TestGetModificationHistory(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv"}}
+	history, err := GetModificationHistory(pv, "ebs.csi.aws.com")
+	if err != nil || len(history) != 0 {
+		t.Fatalf("expected empty history, got %v, %v", history, err)
+	}
+
+	pv.Annotations = map[string]string{
+		"ebs.csi.aws.com/modification-history": `[{"time":"2026-10-16T12:00:00Z","before":{"iops":"3000"},"after":{"iops":"5000"},"pvcResourceVersion":"42","result":"succeeded"}]`,
+	}
+	history, err = GetModificationHistory(pv, "ebs.csi.aws.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ModificationRecord{
+		Time:               time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		Before:             map[string]string{"iops": "3000"},
+		After:              map[string]string{"iops": "5000"},
+		PVCResourceVersion: "42",
+		Result:             ModificationResultSucceeded,
+	}
+	if len(history) != 1 || !history[0].Time.Equal(expected.Time) || history[0].Before["iops"] != "3000" ||
+		history[0].After["iops"] != "5000" || history[0].PVCResourceVersion != "42" || history[0].Result != expected.Result {
+		t.Fatalf("expected %+v, got %+v", expected, history)
+	}
+
+	pv.Annotations["ebs.csi.aws.com/modification-history"] = "not json"
+	if _, err := GetModificationHistory(pv, "ebs.csi.aws.com"); err == nil {
+		t.Fatal("expected an error for an invalid history")
+	}
+}
+
+func TestAppendModificationHistory_CollapsesRepeatedFailures(t *testing.T) {
+	failure := func(minute int, message string) ModificationRecord {
+		return ModificationRecord{
+			Time:    time.Date(2026, 10, 16, 12, minute, 0, 0, time.UTC),
+			Before:  map[string]string{"iops": "3000"},
+			After:   map[string]string{"iops": "5000"},
+			Result:  ModificationResultFailed,
+			Message: message,
+		}
+	}
+
+	var history []ModificationRecord
+	for i, record := range []ModificationRecord{
+		failure(0, "throttled"),
+		failure(1, "throttled"),
+		failure(2, "throttled"),
+		failure(3, "volume is being modified"),
+	} {
+		value, err := AppendModificationHistory(history, record, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if history, err = ParseModificationHistory(value); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+
+	if len(history) != 2 {
+		t.Fatalf("expected identical failures to be collapsed into 2 records, got %+v", history)
+	}
+	if history[0].Count != 3 || !history[0].Time.Equal(time.Date(2026, 10, 16, 12, 2, 0, 0, time.UTC)) {
+		t.Errorf("expected the first record to count 3 failures until 12:02, got %+v", history[0])
+	}
+	if history[1].Count != 0 || history[1].Message != "volume is being modified" {
+		t.Errorf("expected a different failure to be recorded once, got %+v", history[1])
+	}
+}
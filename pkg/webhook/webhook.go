@@ -0,0 +1,197 @@
+// Package webhook implements a validating admission webhook for the
+// "<driver-name>/" modification annotations of PVCs, so that requests the
+// driver would reject are refused when they are applied instead of failing
+// later in the controller.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/controller"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// maxRequestBytes bounds the size of an AdmissionReview read from the API
+// server.
+const maxRequestBytes = 3 * 1024 * 1024
+
+// Validator validates the creation and update of PVCs whose modification
+// annotations change. The annotations are checked against the modification
+// capability advertised by the driver, and refused when the PVC or its PV
+// has a VolumeAttributesClass, as the controller would. Schedules that the
+// controller cannot parse are refused as well.
+type Validator struct {
+	driverName string
+	modifier   modifier.Modifier
+	kubeClient kubernetes.Interface
+}
+
+func NewValidator(driverName string, modifier modifier.Modifier, kubeClient kubernetes.Interface) *Validator {
+	return &Validator{
+		driverName: driverName,
+		modifier:   modifier,
+		kubeClient: kubeClient,
+	}
+}
+
+// ServeHTTP handles an admission.k8s.io/v1 AdmissionReview.
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request is not an AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	response := v.validate(r.Context(), review.Request)
+	response.UID = review.Request.UID
+	review.Request = nil
+	review.Response = response
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		klog.ErrorS(err, "Failed to write admission response")
+	}
+}
+
+func (v *Validator) validate(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Kind.Group != "" || req.Kind.Kind != "PersistentVolumeClaim" {
+		return allowed()
+	}
+
+	var pvc, oldPVC v1.PersistentVolumeClaim
+	if err := json.Unmarshal(req.Object.Raw, &pvc); err != nil {
+		return denied(http.StatusBadRequest, fmt.Sprintf("cannot decode PVC: %v", err))
+	}
+	if req.Operation == admissionv1.Update {
+		if err := json.Unmarshal(req.OldObject.Raw, &oldPVC); err != nil {
+			return denied(http.StatusBadRequest, fmt.Sprintf("cannot decode old PVC: %v", err))
+		}
+	}
+
+	if schedule := v.changedSchedule(oldPVC.Annotations, pvc.Annotations); len(schedule) > 0 {
+		if err := controller.ValidateSchedule(v.driverName, schedule, time.Now()); err != nil {
+			return denied(http.StatusUnprocessableEntity, fmt.Sprintf("invalid %s schedule: %v", v.driverName, err))
+		}
+	}
+
+	params := v.changedParams(oldPVC.Annotations, pvc.Annotations)
+	if len(params) == 0 {
+		return allowed()
+	}
+
+	if err := v.checkVolumeAttributesClass(ctx, &pvc); err != nil {
+		return denied(http.StatusForbidden, err.Error())
+	}
+
+	capability, err := v.modifier.GetModificationCapability()
+	if err != nil {
+		// The controller validates the parameters again before modifying
+		// the volume, so a driver outage must not block PVC updates.
+		klog.ErrorS(err, "Cannot get modification capability, admitting PVC without validation", "pvc", req.Namespace+"/"+req.Name)
+		response := allowed()
+		response.Warnings = []string{fmt.Sprintf("modification annotations were not validated: cannot get modification capability of driver %q", v.driverName)}
+		return response
+	}
+	if err := capability.Validate(params); err != nil {
+		return denied(http.StatusUnprocessableEntity, fmt.Sprintf("invalid %s annotations: %v", v.driverName, err))
+	}
+	return allowed()
+}
+
+// changedParams returns the modification parameters added or changed by the
+// request. Parameters that did not change are not validated again, so that
+// unrelated updates of the PVC are not refused.
+func (v *Validator) changedParams(oldAnnotations, annotations map[string]string) map[string]string {
+	prefix := fmt.Sprintf(controller.AnnotationPrefixPattern, v.driverName)
+	params := make(map[string]string)
+	for key, value := range annotations {
+		if !controller.IsParameterAnnotation(v.driverName, key) {
+			continue
+		}
+		if old, ok := oldAnnotations[key]; ok && old == value {
+			continue
+		}
+		params[strings.TrimPrefix(key, prefix)] = value
+	}
+	return params
+}
+
+// changedSchedule returns the "<driver-name>/modify-after" and
+// "<driver-name>/maintenance-window" annotations added or changed by the
+// request, which the controller would refuse to schedule otherwise.
+func (v *Validator) changedSchedule(oldAnnotations, annotations map[string]string) map[string]string {
+	schedule := make(map[string]string)
+	for _, pattern := range []string{controller.AnnotationModifyAfterPattern, controller.AnnotationMaintenanceWindowPattern} {
+		key := fmt.Sprintf(pattern, v.driverName)
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		if old, ok := oldAnnotations[key]; ok && old == value {
+			continue
+		}
+		schedule[key] = value
+	}
+	return schedule
+}
+
+// checkVolumeAttributesClass refuses modifications of volumes managed through
+// a VolumeAttributesClass, as modifyPVC does.
+func (v *Validator) checkVolumeAttributesClass(ctx context.Context, pvc *v1.PersistentVolumeClaim) error {
+	if pvc.Spec.VolumeAttributesClassName != nil && *pvc.Spec.VolumeAttributesClassName != "" {
+		return fmt.Errorf("PVC %s has a VolumeAttributesClass associated and cannot be modified through %s annotations", pvc.Name, v.driverName)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return nil
+	}
+
+	pv, err := v.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		klog.ErrorS(err, "Cannot get PV, skipping its VolumeAttributesClass check", "pv", pvc.Spec.VolumeName)
+		return nil
+	}
+	if pv.Spec.VolumeAttributesClassName != nil && *pv.Spec.VolumeAttributesClassName != "" {
+		return fmt.Errorf("PV %s has a VolumeAttributesClass associated and cannot be modified through %s annotations", pv.Name, v.driverName)
+	}
+	return nil
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(code int32, message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
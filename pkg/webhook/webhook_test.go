@@ -0,0 +1,278 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	csi "github.com/awslabs/volume-modifier-for-k8s/pkg/client"
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/modifier"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const driverName = "ebs.csi.aws.com"
+
+func newTestValidator(t *testing.T, driverAvailable bool, objects ...runtime.Object) *Validator {
+	t.Helper()
+	client := csi.NewFakeClient(driverName, driverAvailable, false)
+	client.SetModificationCapability(&csi.ModificationCapability{
+		Parameters: map[string]csi.ParameterSchema{
+			"type":       {Name: "type", Type: csi.ParameterTypeString, AllowedValues: []string{"gp2", "gp3", "io2"}},
+			"iops":       {Name: "iops", Type: csi.ParameterTypeInteger},
+			"throughput": {Name: "throughput", Type: csi.ParameterTypeInteger},
+		},
+	})
+	kubeClient := fake.NewClientset(objects...)
+	mod, err := modifier.NewFromClient(driverName, client, kubeClient, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewValidator(driverName, mod, kubeClient)
+}
+
+func newPVC(annotations map[string]string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default", Annotations: annotations},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+	}
+}
+
+func newRequest(t *testing.T, operation admissionv1.Operation, oldPVC, pvc *v1.PersistentVolumeClaim) *admissionv1.AdmissionRequest {
+	t.Helper()
+	req := &admissionv1.AdmissionRequest{
+		UID:       "uid-1",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"},
+		Operation: operation,
+		Namespace: pvc.Namespace,
+		Name:      pvc.Name,
+	}
+	raw, err := json.Marshal(pvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Object.Raw = raw
+	if oldPVC != nil {
+		if req.OldObject.Raw, err = json.Marshal(oldPVC); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return req
+}
+
+func TestValidate(t *testing.T) {
+	vac := "fast"
+	tests := []struct {
+		name          string
+		operation     admissionv1.Operation
+		old           map[string]string
+		new           map[string]string
+		pvcVAC        *string
+		pv            *v1.PersistentVolume
+		expectAllowed bool
+		expectMessage string
+	}{
+		{
+			name:          "valid annotations",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/type": "gp3", "ebs.csi.aws.com/iops": "5000"},
+			expectAllowed: true,
+		},
+		{
+			name:          "misspelled key",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/iopss": "5000"},
+			expectMessage: `parameter "iopss" is not supported by the driver`,
+		},
+		{
+			name:          "invalid value",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/throughput": "fast"},
+			expectMessage: `value "fast" is not an integer`,
+		},
+		{
+			name:          "invalid annotation on create",
+			operation:     admissionv1.Create,
+			new:           map[string]string{"ebs.csi.aws.com/type": "gp9"},
+			expectMessage: `value "gp9" is not one of gp2, gp3, io2`,
+		},
+		{
+			name:          "unchanged invalid annotation",
+			operation:     admissionv1.Update,
+			old:           map[string]string{"ebs.csi.aws.com/iopss": "5000"},
+			new:           map[string]string{"ebs.csi.aws.com/iopss": "5000", "team": "storage"},
+			expectAllowed: true,
+		},
+		{
+			name:          "reserved and status annotations",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/dry-run": "true", "ebs.csi.aws.com/iops-status": "{}"},
+			expectAllowed: true,
+		},
+		{
+			name:          "annotations of another driver",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"other.csi.example.com/iopss": "5000"},
+			expectAllowed: true,
+		},
+		{
+			name:          "invalid modify-after",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/modify-after": "tomorrow"},
+			expectMessage: `invalid modify-after time "tomorrow"`,
+		},
+		{
+			name:          "invalid maintenance window",
+			operation:     admissionv1.Update,
+			old:           map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			new:           map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/maintenance-window": "0 2 * * SAT"},
+			expectMessage: "must be a cron expression followed by a duration",
+		},
+		{
+			name:          "ended maintenance window",
+			operation:     admissionv1.Create,
+			new:           map[string]string{"ebs.csi.aws.com/maintenance-window": "2020-01-01T00:00:00Z/2020-01-02T00:00:00Z"},
+			expectMessage: "has ended",
+		},
+		{
+			name:          "valid maintenance window",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/iops": "5000", "ebs.csi.aws.com/maintenance-window": "0 2 * * SAT 4h"},
+			expectAllowed: true,
+		},
+		{
+			name:          "unchanged invalid schedule",
+			operation:     admissionv1.Update,
+			old:           map[string]string{"ebs.csi.aws.com/modify-after": "tomorrow"},
+			new:           map[string]string{"ebs.csi.aws.com/modify-after": "tomorrow", "team": "storage"},
+			expectAllowed: true,
+		},
+		{
+			name:          "PVC with VAC",
+			operation:     admissionv1.Update,
+			old:           map[string]string{},
+			new:           map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			pvcVAC:        &vac,
+			expectMessage: "PVC data has a VolumeAttributesClass associated",
+		},
+		{
+			name:      "PV with VAC",
+			operation: admissionv1.Update,
+			old:       map[string]string{},
+			new:       map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			pv: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+				Spec:       v1.PersistentVolumeSpec{VolumeAttributesClassName: &vac},
+			},
+			expectMessage: "PV pv-data has a VolumeAttributesClass associated",
+		},
+		{
+			name:          "VAC is ignored without annotation changes",
+			operation:     admissionv1.Update,
+			old:           map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			new:           map[string]string{"ebs.csi.aws.com/iops": "5000"},
+			pvcVAC:        &vac,
+			expectAllowed: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var objects []runtime.Object
+			if tc.pv != nil {
+				objects = append(objects, tc.pv)
+			}
+			validator := newTestValidator(t, true, objects...)
+
+			var oldPVC *v1.PersistentVolumeClaim
+			if tc.old != nil {
+				oldPVC = newPVC(tc.old)
+			}
+			pvc := newPVC(tc.new)
+			pvc.Spec.VolumeAttributesClassName = tc.pvcVAC
+
+			response := validator.validate(context.TODO(), newRequest(t, tc.operation, oldPVC, pvc))
+			if response.Allowed != tc.expectAllowed {
+				t.Fatalf("expected allowed=%v, got %+v", tc.expectAllowed, response)
+			}
+			if tc.expectMessage != "" && !strings.Contains(response.Result.Message, tc.expectMessage) {
+				t.Errorf("expected message to contain %q, got %q", tc.expectMessage, response.Result.Message)
+			}
+		})
+	}
+}
+
+func TestValidate_CapabilityUnavailable(t *testing.T) {
+	validator := newTestValidator(t, false)
+	pvc := newPVC(map[string]string{"ebs.csi.aws.com/iopss": "5000"})
+
+	response := validator.validate(context.TODO(), newRequest(t, admissionv1.Create, nil, pvc))
+	if !response.Allowed {
+		t.Fatalf("expected PVC to be admitted when the driver is unavailable, got %+v", response)
+	}
+	if len(response.Warnings) != 1 {
+		t.Fatalf("expected a warning, got %v", response.Warnings)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	validator := newTestValidator(t, true)
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  newRequest(t, admissionv1.Update, newPVC(nil), newPVC(map[string]string{"ebs.csi.aws.com/iopss": "5000"})),
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	validator.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var got admissionv1.AdmissionReview
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Response == nil || got.Response.UID != "uid-1" || got.Response.Allowed {
+		t.Fatalf("expected the request to be denied, got %+v", got.Response)
+	}
+	if got.Request != nil {
+		t.Error("expected the request not to be echoed back")
+	}
+	if got.Kind != "AdmissionReview" || got.APIVersion != "admission.k8s.io/v1" {
+		t.Errorf("unexpected response type %s/%s", got.APIVersion, got.Kind)
+	}
+}
+
+func TestServeHTTP_BadRequest(t *testing.T) {
+	validator := newTestValidator(t, true)
+
+	recorder := httptest.NewRecorder()
+	validator.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader("{}")))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a review without request, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	validator.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/validate", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405 for GET, got %d", recorder.Code)
+	}
+}